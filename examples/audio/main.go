@@ -13,10 +13,10 @@ func gameLogic() {
 
 	var selectedAudio *woutils.Audio = nil
 
-	audioA := woutils.NewAudio("assets/audio-a.mp3")
+	audioA := woutils.NewAudio(&context, "assets/audio-a.mp3")
 	defer audioA.Destroy()
 
-	audioB := woutils.NewAudio("assets/audio-b.mp3")
+	audioB := woutils.NewAudio(&context, "assets/audio-b.mp3")
 	defer audioB.Destroy()
 
 	playButton := woutils.NewButtonWithText(&context, "Play")
@@ -8,13 +8,16 @@ import (
 type Settings struct {
 	id            uint32
 	settingsLabel woutils.Text
-	background    woutils.Image
+	background    woutils.NineSlice
 	closeButton   woutils.Button
 	womixins.HideMixin
 }
 
 func NewSettings(context *woutils.GameContext, backgroundPath string) Settings {
-	background := woutils.NewImage(context, backgroundPath)
+	// The wooden panel of the sheet, split by the width of its frame
+	background := woutils.NewNineSlice(context, backgroundPath, 110, 110, 110, 110)
+	background.SetSrcRect(1850, 260, 1830, 1350)
+	background.SetBorderScale(0.5)
 	settingsLabel := woutils.NewText(context, "Settings")
 	closeButton := woutils.NewButtonWithText(context, "Close")
 	viewport := context.GetRenderer().GetViewport() // Get the rendering viewport
@@ -22,9 +25,8 @@ func NewSettings(context *woutils.GameContext, backgroundPath string) Settings {
 	// Viewport Center X and Y
 	vpcX, vpcY := viewport.W/2, viewport.H/2
 
-	background.SetSize(vpcX, vpcY)  // Set the image size to half of the viewport size
-	background.CenterOn(vpcX, vpcY) // Centralize the image on the viewport
-	background.SetSrcRect(2000, 350, 1750, 1200)
+	background.SetSize(vpcX, vpcY)  // Set the panel size to half of the viewport size
+	background.CenterOn(vpcX, vpcY) // Centralize the panel on the viewport
 
 	closeButton.CenterOn(vpcX, vpcY+(2*(vpcY/5)))   // Positions the button near the bottom of the background
 	settingsLabel.CenterOn(vpcX, vpcY-(2*(vpcY/5))) // Positions the text near the top of the background
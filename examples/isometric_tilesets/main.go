@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	woengine "github.com/joaovitor123jv/wo-engine"
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
 	woutils "github.com/joaovitor123jv/wo-engine/wo-utils"
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -14,6 +15,23 @@ import (
 // 		The tile data is stored in a CSV format within the TMX file.
 // 		Please note that this setup works best with the current Tiled Map Editor configurations and may not support other configurations or editors.
 
+// TileHighlight outlines the tile under the mouse cursor, drawn in world space so it follows the camera
+type TileHighlight struct {
+	womixins.HideMixin
+	drawer   woutils.Drawer
+	gameMap  *woutils.GameMap
+	col, row int32
+}
+
+func (th *TileHighlight) Render(context *woutils.GameContext) {
+	if th.gameMap.IsInside(th.col, th.row) {
+		th.drawer.SetColor(255, 255, 0, 60)
+		th.drawer.FillTileDiamond(th.gameMap, th.col, th.row)
+		th.drawer.SetColor(255, 255, 0, 255)
+		th.drawer.TileDiamond(th.gameMap, th.col, th.row)
+	}
+}
+
 func gameLogic() {
 	// Create a new graphics context for rendering the isometric tilemap
 	context := woutils.NewContext("Isometric Tilemap Rendering Example")
@@ -40,6 +58,15 @@ func gameLogic() {
 
 	// Add the game map as a renderable entity within the context
 	context.AddRenderable(&gameMap)
+
+	tileHighlight := TileHighlight{
+		HideMixin: womixins.NewHideMixin(),
+		drawer:    woutils.NewDrawer(&context, woutils.WorldSpace),
+		gameMap:   &gameMap,
+		col:       -1,
+		row:       -1,
+	}
+	context.AddRenderable(&tileHighlight)
 	context.AddRenderable(&fpsViewer)
 
 	// Set up a mouse click listener to handle map movements and zoom functionality
@@ -69,6 +96,8 @@ func gameLogic() {
 
 	// Set up a mouse movement listener to handle map dragging and zoom adjustment
 	context.AddMouseMovementListener(func(x, y int32) bool {
+		tileHighlight.col, tileHighlight.row = gameMap.ScreenToTile(&context, x, y) // Highlight the tile under the cursor
+
 		if isMovingMap { // If map is in moving state
 			context.Camera.Translate(x-movementSourceX, y-movementSourceY) // Translate map based on mouse movement
 			movementSourceX, movementSourceY = x, y                        // Update movement source coordinates
@@ -0,0 +1,235 @@
+package wocollision
+
+import "math"
+
+// Contact describes how two shapes overlap
+type Contact struct {
+	Other  *Collider
+	Normal Vec2    // Direction to push the first shape out of the second one
+	Depth  float64 // Distance to push the first shape along Normal
+}
+
+// placeShape moves the shape to its collider position, returning a Circle or a Polygon in absolute coordinates
+func placeShape(shape Shape, position Vec2) Shape {
+	switch s := shape.(type) {
+	case Rect:
+		return s.toPolygon().translate(position)
+	case Circle:
+		return Circle{X: s.X + position.X, Y: s.Y + position.Y, Radius: s.Radius}
+	case Polygon:
+		return s.translate(position)
+	}
+	return nil
+}
+
+// Collide tests two shapes placed at their positions. When they overlap, it returns
+// the penetration vector (Normal and Depth) to push the first shape out of the second one
+func Collide(a Shape, aPosition Vec2, b Shape, bPosition Vec2) (Contact, bool) {
+	return collidePlaced(placeShape(a, aPosition), placeShape(b, bPosition))
+}
+
+func collidePlaced(a, b Shape) (Contact, bool) {
+	switch first := a.(type) {
+	case Circle:
+		switch second := b.(type) {
+		case Circle:
+			return collideCircles(first, second)
+		case Polygon:
+			return collideCirclePolygon(first, second)
+		}
+	case Polygon:
+		switch second := b.(type) {
+		case Circle:
+			contact, ok := collideCirclePolygon(second, first)
+			contact.Normal = contact.Normal.Scale(-1)
+			return contact, ok
+		case Polygon:
+			return collidePolygons(first, second)
+		}
+	}
+	return Contact{}, false
+}
+
+func collideCircles(a, b Circle) (Contact, bool) {
+	difference := Vec2{X: a.X - b.X, Y: a.Y - b.Y}
+	distance := difference.Length()
+	depth := a.Radius + b.Radius - distance
+	if depth <= 0 {
+		return Contact{}, false
+	}
+
+	normal := difference.Normalize()
+	if distance == 0 {
+		normal = Vec2{X: 1, Y: 0} // Same center, any direction works
+	}
+
+	return Contact{Normal: normal, Depth: depth}, true
+}
+
+func projectPolygon(polygon Polygon, axis Vec2) (minimum, maximum float64) {
+	minimum, maximum = math.Inf(1), math.Inf(-1)
+	for _, point := range polygon.Points {
+		projection := point.Dot(axis)
+		minimum = math.Min(minimum, projection)
+		maximum = math.Max(maximum, projection)
+	}
+	return minimum, maximum
+}
+
+func polygonAxes(polygon Polygon) []Vec2 {
+	axes := make([]Vec2, 0, len(polygon.Points))
+	for index, point := range polygon.Points {
+		next := polygon.Points[(index+1)%len(polygon.Points)]
+		axes = append(axes, next.Sub(point).Perpendicular().Normalize())
+	}
+	return axes
+}
+
+// collidePolygons uses the separating axis theorem: convex polygons overlap if their projections
+// overlap on every edge normal. The smallest overlap is the penetration
+func collidePolygons(a, b Polygon) (Contact, bool) {
+	if len(a.Points) < 3 || len(b.Points) < 3 {
+		return Contact{}, false
+	}
+
+	best := Contact{Depth: math.Inf(1)}
+	for _, axis := range append(polygonAxes(a), polygonAxes(b)...) {
+		minA, maxA := projectPolygon(a, axis)
+		minB, maxB := projectPolygon(b, axis)
+
+		overlap := math.Min(maxA, maxB) - math.Max(minA, minB)
+		if overlap <= 0 {
+			return Contact{}, false
+		}
+
+		if overlap < best.Depth {
+			best = Contact{Normal: axis, Depth: overlap}
+		}
+	}
+
+	if a.center().Sub(b.center()).Dot(best.Normal) < 0 {
+		best.Normal = best.Normal.Scale(-1)
+	}
+
+	return best, true
+}
+
+func collideCirclePolygon(circle Circle, polygon Polygon) (Contact, bool) {
+	if len(polygon.Points) < 3 {
+		return Contact{}, false
+	}
+
+	center := Vec2{X: circle.X, Y: circle.Y}
+
+	// Besides the edges, the axis from the closest vertex separates the circle from the corners
+	closest := polygon.Points[0]
+	for _, point := range polygon.Points[1:] {
+		if point.Sub(center).Length() < closest.Sub(center).Length() {
+			closest = point
+		}
+	}
+
+	axes := polygonAxes(polygon)
+	if cornerAxis := center.Sub(closest).Normalize(); cornerAxis != (Vec2{}) {
+		axes = append(axes, cornerAxis)
+	}
+
+	best := Contact{Depth: math.Inf(1)}
+	for _, axis := range axes {
+		projection := center.Dot(axis)
+		minA, maxA := projection-circle.Radius, projection+circle.Radius
+		minB, maxB := projectPolygon(polygon, axis)
+
+		overlap := math.Min(maxA, maxB) - math.Max(minA, minB)
+		if overlap <= 0 {
+			return Contact{}, false
+		}
+
+		if overlap < best.Depth {
+			best = Contact{Normal: axis, Depth: overlap}
+		}
+	}
+
+	if center.Sub(polygon.center()).Dot(best.Normal) < 0 {
+		best.Normal = best.Normal.Scale(-1)
+	}
+
+	return best, true
+}
+
+// intersectRay returns the distance (as a fraction of direction) where the ray hits the shape,
+// and the surface normal. Shapes containing the origin are not hit
+func intersectRay(shape Shape, origin, direction Vec2) (fraction float64, normal Vec2, ok bool) {
+	switch s := shape.(type) {
+	case Circle:
+		center := Vec2{X: s.X, Y: s.Y}
+		toOrigin := origin.Sub(center)
+
+		a := direction.Dot(direction)
+		b := 2 * toOrigin.Dot(direction)
+		c := toOrigin.Dot(toOrigin) - s.Radius*s.Radius
+		discriminant := b*b - 4*a*c
+		if c <= 0 || discriminant < 0 || a == 0 {
+			return 0, Vec2{}, false
+		}
+
+		fraction = (-b - math.Sqrt(discriminant)) / (2 * a)
+		if fraction < 0 || fraction > 1 {
+			return 0, Vec2{}, false
+		}
+
+		return fraction, origin.Add(direction.Scale(fraction)).Sub(center).Normalize(), true
+	case Polygon:
+		if len(s.Points) < 3 || pointInPolygon(origin, s) {
+			return 0, Vec2{}, false
+		}
+
+		fraction = math.Inf(1)
+		for index, start := range s.Points {
+			edge := s.Points[(index+1)%len(s.Points)].Sub(start)
+
+			denominator := direction.X*edge.Y - direction.Y*edge.X
+			if denominator == 0 {
+				continue // Parallel
+			}
+
+			toStart := start.Sub(origin)
+			rayFraction := (toStart.X*edge.Y - toStart.Y*edge.X) / denominator
+			edgeFraction := (toStart.X*direction.Y - toStart.Y*direction.X) / denominator
+
+			if rayFraction >= 0 && rayFraction <= 1 && edgeFraction >= 0 && edgeFraction <= 1 && rayFraction < fraction {
+				fraction = rayFraction
+				normal = edge.Perpendicular().Normalize()
+				if normal.Dot(direction) > 0 {
+					normal = normal.Scale(-1)
+				}
+			}
+		}
+
+		return fraction, normal, !math.IsInf(fraction, 1)
+	}
+
+	return 0, Vec2{}, false
+}
+
+func pointInPolygon(point Vec2, polygon Polygon) bool {
+	inside := false
+	for index, current := range polygon.Points {
+		previous := polygon.Points[(index+len(polygon.Points)-1)%len(polygon.Points)]
+		if (current.Y > point.Y) != (previous.Y > point.Y) &&
+			point.X < (previous.X-current.X)*(point.Y-current.Y)/(previous.Y-current.Y)+current.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func containsPoint(shape Shape, point Vec2) bool {
+	switch s := shape.(type) {
+	case Circle:
+		return point.Sub(Vec2{X: s.X, Y: s.Y}).Length() <= s.Radius
+	case Polygon:
+		return pointInPolygon(point, s)
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package wocollision
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func nearlyEqual(a, b float64) bool {
+	return math.Abs(a-b) < tolerance
+}
+
+func TestCollide(t *testing.T) {
+	square := NewRect(0, 0, 10, 10)
+	triangle := NewPolygon(Vec2{X: 0, Y: 0}, Vec2{X: 10, Y: 0}, Vec2{X: 0, Y: 10})
+
+	tests := []struct {
+		name      string
+		a         Shape
+		aPosition Vec2
+		b         Shape
+		bPosition Vec2
+		overlaps  bool
+		normal    Vec2
+		depth     float64
+	}{
+		{"rects overlapping on the left", square, Vec2{X: 0, Y: 0}, square, Vec2{X: 8, Y: 0}, true, Vec2{X: -1, Y: 0}, 2},
+		{"rects overlapping below", square, Vec2{X: 0, Y: 7}, square, Vec2{X: 1, Y: 0}, true, Vec2{X: 0, Y: 1}, 3},
+		{"rects touching", square, Vec2{X: 0, Y: 0}, square, Vec2{X: 10, Y: 0}, false, Vec2{}, 0},
+		{"rects apart", square, Vec2{X: 0, Y: 0}, square, Vec2{X: 30, Y: 30}, false, Vec2{}, 0},
+		{"circles overlapping", NewCircle(0, 0, 5), Vec2{X: 0, Y: 0}, NewCircle(0, 0, 5), Vec2{X: 8, Y: 0}, true, Vec2{X: -1, Y: 0}, 2},
+		{"circles apart", NewCircle(0, 0, 5), Vec2{X: 0, Y: 0}, NewCircle(0, 0, 5), Vec2{X: 0, Y: 11}, false, Vec2{}, 0},
+		{"circle above a rect", NewCircle(5, 2, 5), Vec2{X: 0, Y: -5}, square, Vec2{X: 0, Y: 0}, true, Vec2{X: 0, Y: -1}, 2},
+		{"rect below a circle", square, Vec2{X: 0, Y: 0}, NewCircle(5, 2, 5), Vec2{X: 0, Y: -5}, true, Vec2{X: 0, Y: 1}, 2},
+		{"circle near a corner", NewCircle(0, 0, 5), Vec2{X: 14, Y: 14}, square, Vec2{X: 0, Y: 0}, false, Vec2{}, 0},
+		{"rect on the diagonal of a triangle", square, Vec2{X: 4, Y: 4}, triangle, Vec2{X: 0, Y: 0}, true, Vec2{X: math.Sqrt2 / 2, Y: math.Sqrt2 / 2}, math.Sqrt2},
+		{"rect past the diagonal of a triangle", square, Vec2{X: 6, Y: 6}, triangle, Vec2{X: 0, Y: 0}, false, Vec2{}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contact, overlaps := Collide(test.a, test.aPosition, test.b, test.bPosition)
+			if overlaps != test.overlaps {
+				t.Fatalf("overlaps = %v, expected %v", overlaps, test.overlaps)
+			}
+			if !overlaps {
+				return
+			}
+
+			if !nearlyEqual(contact.Depth, test.depth) {
+				t.Errorf("depth = %v, expected %v", contact.Depth, test.depth)
+			}
+			if !nearlyEqual(contact.Normal.X, test.normal.X) || !nearlyEqual(contact.Normal.Y, test.normal.Y) {
+				t.Errorf("normal = %+v, expected %+v", contact.Normal, test.normal)
+			}
+		})
+	}
+}
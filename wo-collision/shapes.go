@@ -0,0 +1,167 @@
+// Package wocollision detects and resolves collisions between shapes.
+//
+// Collisions are computed in ground coordinates (the coordinates of the TMX objects, see
+// GameMap.GroundToWorld), where the walls of an isometric map are aligned to the axes.
+package wocollision
+
+import "math"
+
+type Vec2 struct {
+	X float64
+	Y float64
+}
+
+func (v Vec2) Add(other Vec2) Vec2 {
+	return Vec2{X: v.X + other.X, Y: v.Y + other.Y}
+}
+
+func (v Vec2) Sub(other Vec2) Vec2 {
+	return Vec2{X: v.X - other.X, Y: v.Y - other.Y}
+}
+
+func (v Vec2) Scale(factor float64) Vec2 {
+	return Vec2{X: v.X * factor, Y: v.Y * factor}
+}
+
+func (v Vec2) Dot(other Vec2) float64 {
+	return v.X*other.X + v.Y*other.Y
+}
+
+func (v Vec2) Length() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Normalize returns the vector with length 1, or the zero vector if it has no length
+func (v Vec2) Normalize() Vec2 {
+	length := v.Length()
+	if length == 0 {
+		return Vec2{}
+	}
+	return v.Scale(1 / length)
+}
+
+// Perpendicular returns the vector rotated by 90 degrees
+func (v Vec2) Perpendicular() Vec2 {
+	return Vec2{X: -v.Y, Y: v.X}
+}
+
+func (v Vec2) Rotate(degrees float64) Vec2 {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return Vec2{X: v.X*cos - v.Y*sin, Y: v.X*sin + v.Y*cos}
+}
+
+// Shape is the form of a collider, relative to the collider position.
+// The shapes are Rect, Circle and Polygon.
+type Shape interface {
+	// Bounds returns the axis aligned bounding box of the shape placed at position
+	Bounds(position Vec2) Rect
+}
+
+// Rect is an axis aligned box (AABB)
+type Rect struct {
+	X float64
+	Y float64
+	W float64
+	H float64
+}
+
+func NewRect(x, y, w, h float64) Rect {
+	return Rect{X: x, Y: y, W: w, H: h}
+}
+
+func (r Rect) Bounds(position Vec2) Rect {
+	return Rect{X: r.X + position.X, Y: r.Y + position.Y, W: r.W, H: r.H}
+}
+
+func (r Rect) Overlaps(other Rect) bool {
+	return r.X < other.X+other.W && r.X+r.W > other.X && r.Y < other.Y+other.H && r.Y+r.H > other.Y
+}
+
+func (r Rect) Contains(point Vec2) bool {
+	return point.X >= r.X && point.X <= r.X+r.W && point.Y >= r.Y && point.Y <= r.Y+r.H
+}
+
+func (r Rect) toPolygon() Polygon {
+	return Polygon{Points: []Vec2{
+		{X: r.X, Y: r.Y},
+		{X: r.X + r.W, Y: r.Y},
+		{X: r.X + r.W, Y: r.Y + r.H},
+		{X: r.X, Y: r.Y + r.H},
+	}}
+}
+
+type Circle struct {
+	X      float64 // Center
+	Y      float64
+	Radius float64
+}
+
+func NewCircle(x, y, radius float64) Circle {
+	return Circle{X: x, Y: y, Radius: radius}
+}
+
+func (c Circle) Bounds(position Vec2) Rect {
+	return Rect{
+		X: c.X + position.X - c.Radius,
+		Y: c.Y + position.Y - c.Radius,
+		W: 2 * c.Radius,
+		H: 2 * c.Radius,
+	}
+}
+
+// Polygon must be convex. Concave shapes should be split in convex polygons
+type Polygon struct {
+	Points []Vec2
+}
+
+func NewPolygon(points ...Vec2) Polygon {
+	return Polygon{Points: points}
+}
+
+// NewEllipsePolygon approximates an ellipse (inside the box x, y, w, h) with a polygon
+func NewEllipsePolygon(x, y, w, h float64, segments int) Polygon {
+	points := make([]Vec2, segments)
+	for index := range points {
+		angle := 2 * math.Pi * float64(index) / float64(segments)
+		points[index] = Vec2{
+			X: x + w/2 + math.Cos(angle)*w/2,
+			Y: y + h/2 + math.Sin(angle)*h/2,
+		}
+	}
+	return Polygon{Points: points}
+}
+
+func (p Polygon) Bounds(position Vec2) Rect {
+	if len(p.Points) == 0 {
+		return Rect{X: position.X, Y: position.Y}
+	}
+
+	minPoint, maxPoint := p.Points[0], p.Points[0]
+	for _, point := range p.Points[1:] {
+		minPoint.X, minPoint.Y = math.Min(minPoint.X, point.X), math.Min(minPoint.Y, point.Y)
+		maxPoint.X, maxPoint.Y = math.Max(maxPoint.X, point.X), math.Max(maxPoint.Y, point.Y)
+	}
+
+	return Rect{
+		X: minPoint.X + position.X,
+		Y: minPoint.Y + position.Y,
+		W: maxPoint.X - minPoint.X,
+		H: maxPoint.Y - minPoint.Y,
+	}
+}
+
+func (p Polygon) translate(offset Vec2) Polygon {
+	points := make([]Vec2, len(p.Points))
+	for index, point := range p.Points {
+		points[index] = point.Add(offset)
+	}
+	return Polygon{Points: points}
+}
+
+func (p Polygon) center() Vec2 {
+	var sum Vec2
+	for _, point := range p.Points {
+		sum = sum.Add(point)
+	}
+	return sum.Scale(1 / float64(len(p.Points)))
+}
@@ -0,0 +1,174 @@
+package wocollision
+
+import (
+	"strconv"
+	"strings"
+
+	woutils "github.com/joaovitor123jv/wo-engine/wo-utils"
+)
+
+const ellipseSegments = 12
+
+// AddMapColliders creates static colliders from the map, using the same properties as the pathfinding:
+//   - Objects of the object groups with the property walkable=false (on the group or on the object)
+//   - Collision shapes of the tiles, edited in the Tiled collision editor over the tile image.
+//     The shapes are projected to the ground, so they should be drawn over the tile footprint
+//   - Tiles with walkable=false (on the tile or its layer) without collision shapes block their whole footprint
+//
+// The colliders keep the TMX object (or nil for tiles) as UserData. Returns the created colliders.
+func AddMapColliders(world *CollisionWorld, gameMap *woutils.GameMap) []*Collider {
+	var colliders []*Collider
+
+	for _, objectGroup := range gameMap.GetObjectGroups() {
+		groupWalkable := objectGroup.Properties.GetBool("walkable", true)
+
+		for index := range objectGroup.Objects {
+			object := &objectGroup.Objects[index]
+			if object.Properties.GetBool("walkable", groupWalkable) {
+				continue
+			}
+
+			if shape, ok := ObjectShape(object); ok {
+				collider := NewStaticCollider(shape, object.X, object.Y)
+				collider.UserData = object
+				colliders = append(colliders, collider)
+			}
+		}
+	}
+
+	mapWidth, mapHeight := gameMap.GetMapSize()
+	_, tileHeight := gameMap.GetTileSize()
+	groundTileSize := float64(tileHeight)
+
+	for layer := 0; layer < gameMap.GetLayerCount(); layer++ {
+		layerWalkable := gameMap.GetLayerProperties(layer).GetBool("walkable", true)
+
+		for row := int32(0); row < mapHeight; row++ {
+			for col := int32(0); col < mapWidth; col++ {
+				gid := gameMap.GetTile(layer, col, row)
+				if gid == 0 {
+					continue
+				}
+
+				tileData := gameMap.GetTileData(gid)
+				if tileData != nil && tileData.ObjectGroup != nil && len(tileData.ObjectGroup.Objects) > 0 {
+					for _, shape := range tileShapes(gameMap, col, row, gid, tileData.ObjectGroup) {
+						colliders = append(colliders, NewStaticCollider(shape, 0, 0))
+					}
+					continue
+				}
+
+				if !gameMap.GetTileProperties(gid).GetBool("walkable", layerWalkable) {
+					footprint := NewRect(float64(col)*groundTileSize, float64(row)*groundTileSize, groundTileSize, groundTileSize)
+					colliders = append(colliders, NewStaticCollider(footprint, 0, 0))
+				}
+			}
+		}
+	}
+
+	for _, collider := range colliders {
+		world.Add(collider)
+	}
+
+	return colliders
+}
+
+// ObjectShape converts a TMX object to a shape relative to the object position.
+// Point and polyline objects have no area, so they have no shape.
+func ObjectShape(object *woutils.TmxObject) (Shape, bool) {
+	var polygon Polygon
+
+	switch {
+	case object.Point != nil || object.Polyline != nil:
+		return nil, false
+	case object.Polygon != nil:
+		polygon = Polygon{Points: parsePoints(object.Polygon.Points)}
+	case object.Ellipse != nil:
+		if object.Width == object.Height && object.Rotation == 0 {
+			return NewCircle(object.Width/2, object.Height/2, object.Width/2), true
+		}
+		polygon = NewEllipsePolygon(0, 0, object.Width, object.Height, ellipseSegments)
+	default:
+		if object.Width <= 0 || object.Height <= 0 {
+			return nil, false
+		}
+		if object.Rotation == 0 {
+			return NewRect(0, 0, object.Width, object.Height), true
+		}
+		polygon = NewRect(0, 0, object.Width, object.Height).toPolygon()
+	}
+
+	// Tiled rotates the objects (clockwise, in degrees) around their position
+	if object.Rotation != 0 {
+		for index := range polygon.Points {
+			polygon.Points[index] = polygon.Points[index].Rotate(object.Rotation)
+		}
+	}
+
+	return polygon, len(polygon.Points) >= 3
+}
+
+// parsePoints parses the points attribute of TMX polygons ("x1,y1 x2,y2 ...")
+func parsePoints(points string) []Vec2 {
+	var parsed []Vec2
+	for _, pair := range strings.Fields(points) {
+		x, y, found := strings.Cut(pair, ",")
+		if !found {
+			continue
+		}
+
+		parsedX, errX := strconv.ParseFloat(x, 64)
+		parsedY, errY := strconv.ParseFloat(y, 64)
+		if errX == nil && errY == nil {
+			parsed = append(parsed, Vec2{X: parsedX, Y: parsedY})
+		}
+	}
+	return parsed
+}
+
+// tileShapes projects the collision shapes of a tile (in pixels of the tile image) to ground coordinates
+func tileShapes(gameMap *woutils.GameMap, col, row int32, gid uint32, objectGroup *woutils.TmxObjectGroup) []Shape {
+	imageRect := gameMap.GetTileImageRect(col, row, gid)
+
+	toGround := func(point Vec2) Vec2 {
+		// Flipped tiles have flipped shapes too
+		if gid&woutils.TILE_FLIPPED_HORIZONTALLY != 0 {
+			point.X = float64(imageRect.W) - point.X
+		}
+		if gid&woutils.TILE_FLIPPED_VERTICALLY != 0 {
+			point.Y = float64(imageRect.H) - point.Y
+		}
+
+		x, y := gameMap.WorldToGround(float64(imageRect.X)+point.X, float64(imageRect.Y)+point.Y)
+		return Vec2{X: x, Y: y}
+	}
+
+	var shapes []Shape
+	for index := range objectGroup.Objects {
+		object := &objectGroup.Objects[index]
+
+		shape, ok := ObjectShape(object)
+		if !ok {
+			continue
+		}
+
+		// Every shape becomes a polygon, because the projection turns boxes into diamonds
+		var polygon Polygon
+		switch s := shape.(type) {
+		case Rect:
+			polygon = s.toPolygon()
+		case Circle:
+			polygon = NewEllipsePolygon(s.X-s.Radius, s.Y-s.Radius, 2*s.Radius, 2*s.Radius, ellipseSegments)
+		case Polygon:
+			polygon = s
+		}
+
+		points := make([]Vec2, len(polygon.Points))
+		for pointIndex, point := range polygon.Points {
+			points[pointIndex] = toGround(point.Add(Vec2{X: object.X, Y: object.Y}))
+		}
+		shapes = append(shapes, Polygon{Points: points})
+	}
+
+	return shapes
+}
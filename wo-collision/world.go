@@ -0,0 +1,309 @@
+package wocollision
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	ALL_LAYERS uint32 = 0xFFFFFFFF
+
+	maxResolveIterations = 4
+)
+
+type Collider struct {
+	Shape    Shape
+	Layer    uint32 // Bits of the layers this collider belongs to
+	Mask     uint32 // Bits of the layers this collider collides with
+	Static   bool   // Static colliders never move (walls, trees...)
+	UserData any    // Anything the game wants to keep with the collider (e.g. the entity)
+	position Vec2
+	world    *CollisionWorld
+	cells    []cellKey
+	mark     uint64 // Last query that found this collider, to avoid duplicates
+}
+
+func NewCollider(shape Shape, x, y float64) *Collider {
+	return &Collider{
+		Shape:    shape,
+		Layer:    1,
+		Mask:     ALL_LAYERS,
+		Static:   false,
+		UserData: nil,
+		position: Vec2{X: x, Y: y},
+	}
+}
+
+func NewStaticCollider(shape Shape, x, y float64) *Collider {
+	collider := NewCollider(shape, x, y)
+	collider.Static = true
+	return collider
+}
+
+func (c *Collider) GetPosition() Vec2 {
+	return c.position
+}
+
+func (c *Collider) GetBounds() Rect {
+	return c.Shape.Bounds(c.position)
+}
+
+func (c *Collider) collidesWith(other *Collider) bool {
+	return c != other && c.Mask&other.Layer != 0
+}
+
+type cellKey struct {
+	x int32
+	y int32
+}
+
+// CollisionWorld keeps the colliders in a spatial hash (a grid of cells), so only
+// the colliders near each other are tested
+type CollisionWorld struct {
+	cellSize  float64
+	cells     map[cellKey][]*Collider
+	colliders []*Collider
+	queryMark uint64
+}
+
+// NewCollisionWorld creates a world with cells of cellSize. A good cell size is about
+// twice the size of the moving colliders (e.g. the tile height)
+func NewCollisionWorld(cellSize float64) *CollisionWorld {
+	if cellSize <= 0 {
+		cellSize = 64
+	}
+
+	return &CollisionWorld{
+		cellSize:  cellSize,
+		cells:     make(map[cellKey][]*Collider),
+		colliders: nil,
+		queryMark: 0,
+	}
+}
+
+func (cw *CollisionWorld) Add(collider *Collider) {
+	if collider.world != nil {
+		collider.world.Remove(collider)
+	}
+
+	collider.world = cw
+	cw.colliders = append(cw.colliders, collider)
+	cw.insert(collider)
+}
+
+func (cw *CollisionWorld) Remove(collider *Collider) {
+	if collider.world != cw {
+		return
+	}
+
+	cw.removeFromCells(collider)
+	cw.colliders = slices.DeleteFunc(cw.colliders, func(c *Collider) bool {
+		return c == collider
+	})
+	collider.world = nil
+}
+
+func (cw *CollisionWorld) GetColliders() []*Collider {
+	return cw.colliders
+}
+
+func (cw *CollisionWorld) cellRange(bounds Rect) (minKey, maxKey cellKey) {
+	minKey = cellKey{x: int32(math.Floor(bounds.X / cw.cellSize)), y: int32(math.Floor(bounds.Y / cw.cellSize))}
+	maxKey = cellKey{x: int32(math.Floor((bounds.X + bounds.W) / cw.cellSize)), y: int32(math.Floor((bounds.Y + bounds.H) / cw.cellSize))}
+	return minKey, maxKey
+}
+
+func (cw *CollisionWorld) insert(collider *Collider) {
+	minKey, maxKey := cw.cellRange(collider.GetBounds())
+
+	collider.cells = collider.cells[:0]
+	for y := minKey.y; y <= maxKey.y; y++ {
+		for x := minKey.x; x <= maxKey.x; x++ {
+			key := cellKey{x: x, y: y}
+			cw.cells[key] = append(cw.cells[key], collider)
+			collider.cells = append(collider.cells, key)
+		}
+	}
+}
+
+func (cw *CollisionWorld) removeFromCells(collider *Collider) {
+	for _, key := range collider.cells {
+		cell := slices.DeleteFunc(cw.cells[key], func(c *Collider) bool {
+			return c == collider
+		})
+
+		if len(cell) == 0 {
+			delete(cw.cells, key)
+		} else {
+			cw.cells[key] = cell
+		}
+	}
+	collider.cells = collider.cells[:0]
+}
+
+// SetPosition teleports the collider, without resolving collisions
+func (cw *CollisionWorld) SetPosition(collider *Collider, x, y float64) {
+	collider.position = Vec2{X: x, Y: y}
+	if collider.world == cw {
+		cw.removeFromCells(collider)
+		cw.insert(collider)
+	}
+}
+
+// candidates returns the colliders whose cells touch the area (broad phase), without duplicates
+func (cw *CollisionWorld) candidates(area Rect) []*Collider {
+	cw.queryMark++
+	minKey, maxKey := cw.cellRange(area)
+
+	var found []*Collider
+	for y := minKey.y; y <= maxKey.y; y++ {
+		for x := minKey.x; x <= maxKey.x; x++ {
+			for _, collider := range cw.cells[cellKey{x: x, y: y}] {
+				if collider.mark != cw.queryMark && collider.GetBounds().Overlaps(area) {
+					collider.mark = cw.queryMark
+					found = append(found, collider)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// GetContacts returns every collider overlapping the collider (respecting its mask),
+// with the penetration vectors to push it out of each one
+func (cw *CollisionWorld) GetContacts(collider *Collider) []Contact {
+	placed := placeShape(collider.Shape, collider.position)
+
+	var contacts []Contact
+	for _, other := range cw.candidates(collider.GetBounds()) {
+		if !collider.collidesWith(other) {
+			continue
+		}
+
+		if contact, ok := collidePlaced(placed, placeShape(other.Shape, other.position)); ok {
+			contact.Other = other
+			contacts = append(contacts, contact)
+		}
+	}
+	return contacts
+}
+
+// Move moves the collider by dx and dy, stopping at the colliders it collides with.
+// The movement against a wall is projected along it, so the collider slides instead of stopping.
+// Returns the movement actually done.
+func (cw *CollisionWorld) Move(collider *Collider, dx, dy float64) Vec2 {
+	start := collider.position
+	movement := Vec2{X: dx, Y: dy}
+
+	// Moves in steps smaller than the collider, so fast colliders don't pass through thin walls
+	bounds := collider.Shape.Bounds(Vec2{})
+	stepSize := math.Max(math.Min(bounds.W, bounds.H)/2, 1)
+	steps := int(math.Ceil(movement.Length() / stepSize))
+	step := movement.Scale(1 / float64(max(steps, 1)))
+
+	for range steps {
+		collider.position = collider.position.Add(step)
+		cw.resolve(collider)
+	}
+
+	if collider.world == cw {
+		cw.removeFromCells(collider)
+		cw.insert(collider)
+	}
+
+	return collider.position.Sub(start)
+}
+
+// resolve pushes the collider out of the colliders it overlaps, the deepest first
+func (cw *CollisionWorld) resolve(collider *Collider) {
+	for range maxResolveIterations {
+		contacts := cw.GetContacts(collider)
+		if len(contacts) == 0 {
+			return
+		}
+
+		deepest := contacts[0]
+		for _, contact := range contacts[1:] {
+			if contact.Depth > deepest.Depth {
+				deepest = contact
+			}
+		}
+
+		collider.position = collider.position.Add(deepest.Normal.Scale(deepest.Depth))
+	}
+}
+
+// QueryRect returns the colliders in the layers of mask overlapping the rectangle
+func (cw *CollisionWorld) QueryRect(area Rect, mask uint32) []*Collider {
+	return cw.queryShape(area, Vec2{}, mask)
+}
+
+// QueryCircle returns the colliders in the layers of mask overlapping the circle
+func (cw *CollisionWorld) QueryCircle(x, y, radius float64, mask uint32) []*Collider {
+	return cw.queryShape(NewCircle(x, y, radius), Vec2{}, mask)
+}
+
+// QueryPoint returns the colliders in the layers of mask containing the point
+func (cw *CollisionWorld) QueryPoint(x, y float64, mask uint32) []*Collider {
+	point := Vec2{X: x, Y: y}
+
+	var found []*Collider
+	for _, collider := range cw.candidates(Rect{X: x, Y: y}) {
+		if collider.Layer&mask != 0 && containsPoint(placeShape(collider.Shape, collider.position), point) {
+			found = append(found, collider)
+		}
+	}
+	return found
+}
+
+func (cw *CollisionWorld) queryShape(shape Shape, position Vec2, mask uint32) []*Collider {
+	placed := placeShape(shape, position)
+
+	var found []*Collider
+	for _, collider := range cw.candidates(shape.Bounds(position)) {
+		if collider.Layer&mask == 0 {
+			continue
+		}
+
+		if _, ok := collidePlaced(placed, placeShape(collider.Shape, collider.position)); ok {
+			found = append(found, collider)
+		}
+	}
+	return found
+}
+
+type RaycastHit struct {
+	Collider *Collider
+	Point    Vec2
+	Normal   Vec2
+	Distance float64
+}
+
+// Raycast returns the first collider in the layers of mask hit by the segment from (fromX, fromY) to (toX, toY).
+// Colliders containing the start point (e.g. the collider casting the ray) are ignored
+func (cw *CollisionWorld) Raycast(fromX, fromY, toX, toY float64, mask uint32) (RaycastHit, bool) {
+	origin := Vec2{X: fromX, Y: fromY}
+	direction := Vec2{X: toX - fromX, Y: toY - fromY}
+	area := Rect{X: math.Min(fromX, toX), Y: math.Min(fromY, toY), W: math.Abs(direction.X), H: math.Abs(direction.Y)}
+
+	hit := RaycastHit{}
+	bestFraction := math.Inf(1)
+	for _, collider := range cw.candidates(area) {
+		if collider.Layer&mask == 0 {
+			continue
+		}
+
+		fraction, normal, ok := intersectRay(placeShape(collider.Shape, collider.position), origin, direction)
+		if ok && fraction < bestFraction {
+			bestFraction = fraction
+			hit = RaycastHit{
+				Collider: collider,
+				Point:    origin.Add(direction.Scale(fraction)),
+				Normal:   normal,
+				Distance: direction.Length() * fraction,
+			}
+		}
+	}
+
+	return hit, hit.Collider != nil
+}
@@ -0,0 +1,53 @@
+package wocollision
+
+import "testing"
+
+func TestMove(t *testing.T) {
+	start := Vec2{X: 60, Y: 50}
+
+	tests := []struct {
+		name     string
+		dx       float64
+		dy       float64
+		expected Vec2 // Position of the collider after moving
+	}{
+		{"free movement", -20, -10, Vec2{X: 40, Y: 40}},
+		{"stops at the wall", 50, 0, Vec2{X: 90, Y: 50}},
+		{"slides down along the wall", 50, 30, Vec2{X: 90, Y: 80}},
+		{"slides up along the wall", 50, -30, Vec2{X: 90, Y: 20}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			world := NewCollisionWorld(32)
+			world.Add(NewStaticCollider(NewRect(0, 0, 20, 200), 100, 0))
+			player := NewCollider(NewRect(0, 0, 10, 10), start.X, start.Y)
+			world.Add(player)
+
+			moved := world.Move(player, test.dx, test.dy)
+			position := player.GetPosition()
+			if !nearlyEqual(position.X, test.expected.X) || !nearlyEqual(position.Y, test.expected.Y) {
+				t.Fatalf("position = %+v, expected %+v", position, test.expected)
+			}
+			if !nearlyEqual(moved.X, test.expected.X-start.X) || !nearlyEqual(moved.Y, test.expected.Y-start.Y) {
+				t.Fatalf("movement = %+v, expected the difference of the positions", moved)
+			}
+		})
+	}
+}
+
+func TestMoveRespectsMask(t *testing.T) {
+	world := NewCollisionWorld(32)
+	wall := NewStaticCollider(NewRect(0, 0, 20, 200), 100, 0)
+	wall.Layer = 2
+	world.Add(wall)
+
+	player := NewCollider(NewRect(0, 0, 10, 10), 60, 50)
+	player.Mask = 1
+	world.Add(player)
+
+	world.Move(player, 100, 0)
+	if position := player.GetPosition(); !nearlyEqual(position.X, 160) {
+		t.Fatalf("position = %+v, expected to pass through a wall in another layer", position)
+	}
+}
@@ -37,6 +37,10 @@ func (r *RectMixin) SetPosition(x, y int32) {
 	r.Y = y
 }
 
+func (r *RectMixin) GetPosition() (x, y int32) {
+	return r.X, r.Y
+}
+
 func (r *RectMixin) SetSize(width, height int32) {
 	r.W = width
 	r.H = height
@@ -0,0 +1,217 @@
+package wopathfinding
+
+import (
+	"container/heap"
+	"math"
+
+	woutils "github.com/joaovitor123jv/wo-engine/wo-utils"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type Movement uint8
+type CornerRule uint8
+
+const (
+	FourDirections Movement = iota
+	EightDirections
+)
+
+// Rules for diagonal moves, when the two cells beside the diagonal (the corners) are not walkable
+const (
+	NeverCutCorners     CornerRule = iota // Diagonals only if both corners are walkable
+	CutCornersIfOneFree                   // Diagonals if at least one corner is walkable
+	AlwaysCutCorners                      // Diagonals even between two blocked corners
+)
+
+type PathOptions struct {
+	Movement Movement
+	Corners  CornerRule
+	MaxNodes int // Maximum number of cells expanded before giving up. 0 means no limit
+}
+
+func NewPathOptions() PathOptions {
+	return PathOptions{
+		Movement: EightDirections,
+		Corners:  NeverCutCorners,
+		MaxNodes: 0,
+	}
+}
+
+type PathResult struct {
+	Tiles []sdl.Point // From the start to the goal cell, both included
+	Cost  float32
+	Found bool
+}
+
+var directions = [8]sdl.Point{
+	{X: 1, Y: 0}, {X: -1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: -1},
+	{X: 1, Y: 1}, {X: -1, Y: 1}, {X: 1, Y: -1}, {X: -1, Y: -1},
+}
+
+type openNode struct {
+	index int32
+	score float32 // Cost from the start plus the heuristic
+}
+
+type openList []openNode
+
+func (ol openList) Len() int           { return len(ol) }
+func (ol openList) Less(i, j int) bool { return ol[i].score < ol[j].score }
+func (ol openList) Swap(i, j int)      { ol[i], ol[j] = ol[j], ol[i] }
+func (ol *openList) Push(node any)     { *ol = append(*ol, node.(openNode)) }
+func (ol *openList) Pop() any {
+	old := *ol
+	node := old[len(old)-1]
+	*ol = old[:len(old)-1]
+	return node
+}
+
+func (ng *NavGrid) heuristic(col, row, goalCol, goalRow int32, movement Movement) float32 {
+	dx := math.Abs(float64(col - goalCol))
+	dy := math.Abs(float64(row - goalRow))
+
+	if movement == FourDirections {
+		return float32(dx+dy) * ng.minCost
+	}
+
+	// Octile distance
+	return float32(math.Max(dx, dy)+(math.Sqrt2-1)*math.Min(dx, dy)) * ng.minCost
+}
+
+func (ng *NavGrid) canMoveDiagonally(col, row int32, direction sdl.Point, corners CornerRule) bool {
+	firstCorner := ng.isWalkable(col+direction.X, row)
+	secondCorner := ng.isWalkable(col, row+direction.Y)
+
+	switch corners {
+	case NeverCutCorners:
+		return firstCorner && secondCorner
+	case CutCornersIfOneFree:
+		return firstCorner || secondCorner
+	}
+	return true
+}
+
+// FindPath searches the cheapest path between two cells with A*.
+// It is safe to call from any goroutine (see FindPathAsync). The search runs on a copy of the grid,
+// so changes made while it runs don't wait for it, and are not seen by it
+func (ng *NavGrid) FindPath(fromCol, fromRow, toCol, toRow int32, options PathOptions) PathResult {
+	return ng.snapshot().findPath(fromCol, fromRow, toCol, toRow, options)
+}
+
+// findPath searches the path without locking, on a grid no other goroutine changes (see snapshot)
+func (ng *NavGrid) findPath(fromCol, fromRow, toCol, toRow int32, options PathOptions) PathResult {
+	if !ng.isWalkable(fromCol, fromRow) || !ng.isWalkable(toCol, toRow) {
+		return PathResult{}
+	}
+
+	cellCount := ng.width * ng.height
+	costs := make([]float32, cellCount)
+	parents := make([]int32, cellCount)
+	closed := make([]bool, cellCount)
+	for index := range costs {
+		costs[index] = float32(math.Inf(1))
+		parents[index] = -1
+	}
+
+	start := fromRow*ng.width + fromCol
+	goal := toRow*ng.width + toCol
+	costs[start] = 0
+
+	open := &openList{{index: start, score: ng.heuristic(fromCol, fromRow, toCol, toRow, options.Movement)}}
+	directionCount := 4
+	if options.Movement == EightDirections {
+		directionCount = 8
+	}
+
+	expanded := 0
+	for open.Len() > 0 {
+		current := heap.Pop(open).(openNode).index
+		if closed[current] {
+			continue // Outdated entry, the cell was reached again with a lower cost
+		}
+		closed[current] = true
+
+		if current == goal {
+			return ng.buildPath(parents, goal, costs[goal])
+		}
+
+		expanded++
+		if options.MaxNodes > 0 && expanded >= options.MaxNodes {
+			break
+		}
+
+		col, row := current%ng.width, current/ng.width
+		for _, direction := range directions[:directionCount] {
+			nextCol, nextRow := col+direction.X, row+direction.Y
+			if !ng.isWalkable(nextCol, nextRow) {
+				continue
+			}
+
+			stepCost := float32(1)
+			if direction.X != 0 && direction.Y != 0 {
+				if !ng.canMoveDiagonally(col, row, direction, options.Corners) {
+					continue
+				}
+				stepCost = math.Sqrt2
+			}
+
+			next := nextRow*ng.width + nextCol
+			cost := costs[current] + stepCost*ng.costs[next]
+			if closed[next] || cost >= costs[next] {
+				continue
+			}
+
+			costs[next] = cost
+			parents[next] = current
+			heap.Push(open, openNode{index: next, score: cost + ng.heuristic(nextCol, nextRow, toCol, toRow, options.Movement)})
+		}
+	}
+
+	return PathResult{}
+}
+
+func (ng *NavGrid) buildPath(parents []int32, goal int32, cost float32) PathResult {
+	var tiles []sdl.Point
+	for index := goal; index != -1; index = parents[index] {
+		tiles = append(tiles, sdl.Point{X: index % ng.width, Y: index / ng.width})
+	}
+
+	// Built from the goal, reversed to start from the start
+	for i, j := 0, len(tiles)-1; i < j; i, j = i+1, j-1 {
+		tiles[i], tiles[j] = tiles[j], tiles[i]
+	}
+
+	return PathResult{
+		Tiles: tiles,
+		Cost:  cost,
+		Found: true,
+	}
+}
+
+// FindPathAsync searches the path in another goroutine, so big searches don't block the frame.
+// The result is sent to the returned channel, which can be checked on each frame without blocking:
+//
+//	select {
+//	case result := <-pathChannel:
+//		...
+//	default:
+//	}
+func (ng *NavGrid) FindPathAsync(fromCol, fromRow, toCol, toRow int32, options PathOptions) <-chan PathResult {
+	result := make(chan PathResult, 1)
+
+	go func() {
+		result <- ng.FindPath(fromCol, fromRow, toCol, toRow, options)
+	}()
+
+	return result
+}
+
+// PathToWorld converts the path cells to the center of the tiles, in map coordinates
+func PathToWorld(gameMap *woutils.GameMap, tiles []sdl.Point) []sdl.Point {
+	points := make([]sdl.Point, len(tiles))
+	for index, tile := range tiles {
+		points[index].X, points[index].Y = gameMap.GetTileCenter(tile.X, tile.Y)
+	}
+
+	return points
+}
@@ -0,0 +1,208 @@
+package wopathfinding
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// newTestGrid creates a grid from rows of cells, where '#' is not walkable and digits are costs
+func newTestGrid(rows ...string) *NavGrid {
+	navGrid := NewNavGrid(int32(len(rows[0])), int32(len(rows)))
+	for row, cells := range rows {
+		for col, cell := range cells {
+			switch {
+			case cell == '#':
+				navGrid.SetWalkable(int32(col), int32(row), false)
+			case cell >= '1' && cell <= '9':
+				navGrid.SetCost(int32(col), int32(row), float32(cell-'0'))
+			}
+		}
+	}
+	return navGrid
+}
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		grid     []string
+		from     sdl.Point
+		to       sdl.Point
+		movement Movement
+		corners  CornerRule
+		found    bool
+		cost     float64
+		tiles    []sdl.Point // Checked when not nil
+	}{
+		{
+			name:     "straight line",
+			grid:     []string{".....", ".....", "....."},
+			from:     sdl.Point{X: 0, Y: 1},
+			to:       sdl.Point{X: 4, Y: 1},
+			movement: FourDirections,
+			found:    true,
+			cost:     4,
+			tiles:    []sdl.Point{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 3, Y: 1}, {X: 4, Y: 1}},
+		},
+		{
+			name:     "diagonal",
+			grid:     []string{"....", "....", "....", "...."},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 3, Y: 3},
+			movement: EightDirections,
+			found:    true,
+			cost:     3 * math.Sqrt2,
+		},
+		{
+			name:     "around a wall",
+			grid:     []string{"..#..", "..#..", "....."},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 4, Y: 0},
+			movement: FourDirections,
+			found:    true,
+			cost:     8,
+		},
+		{
+			name:     "around an expensive cell",
+			grid:     []string{"..9..", "....."},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 4, Y: 0},
+			movement: FourDirections,
+			found:    true,
+			cost:     6,
+		},
+		{
+			name:     "goal not walkable",
+			grid:     []string{"...", "..#"},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 2, Y: 1},
+			movement: EightDirections,
+			found:    false,
+		},
+		{
+			name:     "goal walled in",
+			grid:     []string{"..#.", "..#.", "..#."},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 3, Y: 2},
+			movement: EightDirections,
+			corners:  AlwaysCutCorners,
+			found:    false,
+		},
+		{
+			name:     "never cut a blocked corner",
+			grid:     []string{".#", ".."},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 1, Y: 1},
+			movement: EightDirections,
+			corners:  NeverCutCorners,
+			found:    true,
+			cost:     2,
+			tiles:    []sdl.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}},
+		},
+		{
+			name:     "cut a corner if the other is free",
+			grid:     []string{".#", ".."},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 1, Y: 1},
+			movement: EightDirections,
+			corners:  CutCornersIfOneFree,
+			found:    true,
+			cost:     math.Sqrt2,
+			tiles:    []sdl.Point{{X: 0, Y: 0}, {X: 1, Y: 1}},
+		},
+		{
+			name:     "don't cut two blocked corners",
+			grid:     []string{".#", "#."},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 1, Y: 1},
+			movement: EightDirections,
+			corners:  CutCornersIfOneFree,
+			found:    false,
+		},
+		{
+			name:     "always cut corners",
+			grid:     []string{".#", "#."},
+			from:     sdl.Point{X: 0, Y: 0},
+			to:       sdl.Point{X: 1, Y: 1},
+			movement: EightDirections,
+			corners:  AlwaysCutCorners,
+			found:    true,
+			cost:     math.Sqrt2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := NewPathOptions()
+			options.Movement = test.movement
+			options.Corners = test.corners
+
+			result := newTestGrid(test.grid...).FindPath(test.from.X, test.from.Y, test.to.X, test.to.Y, options)
+			if result.Found != test.found {
+				t.Fatalf("found = %v, expected %v", result.Found, test.found)
+			}
+			if !result.Found {
+				return
+			}
+
+			if math.Abs(float64(result.Cost)-test.cost) > 1e-4 {
+				t.Errorf("cost = %v, expected %v", result.Cost, test.cost)
+			}
+			if result.Tiles[0] != test.from || result.Tiles[len(result.Tiles)-1] != test.to {
+				t.Errorf("path %v does not go from %v to %v", result.Tiles, test.from, test.to)
+			}
+			if test.tiles != nil && !slices.Equal(result.Tiles, test.tiles) {
+				t.Errorf("path = %v, expected %v", result.Tiles, test.tiles)
+			}
+		})
+	}
+}
+
+func TestFindPathMaxNodes(t *testing.T) {
+	navGrid := newTestGrid("..........", "..........", "..........")
+	options := NewPathOptions()
+
+	options.MaxNodes = 3
+	if result := navGrid.FindPath(0, 0, 9, 2, options); result.Found {
+		t.Fatal("found a path expanding fewer cells than its length")
+	}
+
+	options.MaxNodes = 100
+	if result := navGrid.FindPath(0, 0, 9, 2, options); !result.Found {
+		t.Fatal("did not find the path")
+	}
+}
+
+func TestFindPathThroughOpenedCorridor(t *testing.T) {
+	tests := []struct {
+		name  string
+		close func(navGrid *NavGrid, col int32, closed bool)
+	}{
+		{"blocked", func(navGrid *NavGrid, col int32, closed bool) { navGrid.SetBlocked(col, 1, closed) }},
+		{"not walkable", func(navGrid *NavGrid, col int32, closed bool) { navGrid.SetWalkable(col, 1, !closed) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// A cheap corridor below an expensive row, closed when the grid is built
+			navGrid := newTestGrid("55555", ".....")
+			for col := int32(0); col < 5; col++ {
+				test.close(navGrid, col, true)
+			}
+			navGrid.updateMinCost()
+
+			for col := int32(0); col < 5; col++ {
+				test.close(navGrid, col, false)
+			}
+
+			options := NewPathOptions()
+			options.Movement = FourDirections
+			result := navGrid.FindPath(0, 0, 4, 0, options)
+			if !result.Found || result.Cost != 10 {
+				t.Fatalf("found = %v, cost = %v, expected the path through the corridor, with cost 10", result.Found, result.Cost)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Package wopathfinding finds paths over the tile grid of a GameMap.
+//
+// The navigation grid is read from the custom properties set in Tiled:
+//   - "walkable" (bool, default true): on a tile (tileset) or on a layer, for all its tiles
+//   - "cost" (float, default 1): cost to enter the tile. The topmost tile (or layer) defining it wins
+//   - "walkable" (bool) on an object group or on an object: objects with walkable=false block the tiles they cover
+//
+// Cells without any tile are not walkable.
+package wopathfinding
+
+import (
+	"math"
+	"slices"
+	"sync"
+
+	woutils "github.com/joaovitor123jv/wo-engine/wo-utils"
+)
+
+type NavGrid struct {
+	width    int32
+	height   int32
+	walkable []bool // Read from the tiles
+	blocked  []bool // Set by objects and obstacles added at runtime
+	costs    []float32
+	minCost  float32 // Lowest cost of the grid, keeps the A* heuristic admissible
+	lock     sync.RWMutex
+}
+
+// NewNavGrid creates a grid where every cell is walkable, with cost 1
+func NewNavGrid(width, height int32) *NavGrid {
+	walkable := make([]bool, width*height)
+	blocked := make([]bool, width*height)
+	costs := make([]float32, width*height)
+	for index := range walkable {
+		walkable[index] = true
+		costs[index] = 1
+	}
+
+	return &NavGrid{
+		width:    width,
+		height:   height,
+		walkable: walkable,
+		blocked:  blocked,
+		costs:    costs,
+		minCost:  1,
+	}
+}
+
+// NewNavGridFromMap builds the navigation grid from the tiles, layers and objects of the map
+func NewNavGridFromMap(gameMap *woutils.GameMap) *NavGrid {
+	width, height := gameMap.GetMapSize()
+	navGrid := NewNavGrid(width, height)
+
+	for row := int32(0); row < height; row++ {
+		for col := int32(0); col < width; col++ {
+			walkable, cost := readCell(gameMap, col, row)
+			navGrid.walkable[row*width+col] = walkable
+			navGrid.costs[row*width+col] = cost
+		}
+	}
+
+	for _, objectGroup := range gameMap.GetObjectGroups() {
+		groupWalkable := objectGroup.Properties.GetBool("walkable", true)
+
+		for _, object := range objectGroup.Objects {
+			if object.Properties.GetBool("walkable", groupWalkable) {
+				continue
+			}
+
+			startCol, startRow := gameMap.ObjectToTile(object.X, object.Y)
+			endCol, endRow := gameMap.ObjectToTile(object.X+object.Width, object.Y+object.Height)
+
+			// Point objects (and objects smaller than a tile) still block the tile they are in
+			firstCol, firstRow := int32(math.Floor(startCol)), int32(math.Floor(startRow))
+			lastCol := max(firstCol, int32(math.Ceil(endCol))-1)
+			lastRow := max(firstRow, int32(math.Ceil(endRow))-1)
+
+			for row := firstRow; row <= lastRow; row++ {
+				for col := firstCol; col <= lastCol; col++ {
+					navGrid.SetBlocked(col, row, true)
+				}
+			}
+		}
+	}
+
+	navGrid.updateMinCost()
+	return navGrid
+}
+
+func readCell(gameMap *woutils.GameMap, col, row int32) (walkable bool, cost float32) {
+	hasTile := false
+	walkable = true
+	cost = 1
+
+	for layer := 0; layer < gameMap.GetLayerCount(); layer++ {
+		gid := gameMap.GetTile(layer, col, row)
+		if gid == 0 {
+			continue
+		}
+		hasTile = true
+
+		layerProperties := gameMap.GetLayerProperties(layer)
+		tileProperties := gameMap.GetTileProperties(gid)
+
+		if !tileProperties.GetBool("walkable", layerProperties.GetBool("walkable", true)) {
+			walkable = false
+		}
+
+		cost = float32(tileProperties.GetFloat("cost", layerProperties.GetFloat("cost", float64(cost))))
+	}
+
+	// Costs must be positive for A* to work
+	if cost <= 0 {
+		cost = 1
+	}
+
+	return hasTile && walkable, cost
+}
+
+// updateMinCost finds the lowest cost of the grid. Cells not walkable count too,
+// so the heuristic stays admissible when they become walkable later
+func (ng *NavGrid) updateMinCost() {
+	ng.minCost = 0
+	for _, cost := range ng.costs {
+		if cost > 0 && (ng.minCost == 0 || cost < ng.minCost) {
+			ng.minCost = cost
+		}
+	}
+
+	if ng.minCost == 0 {
+		ng.minCost = 1
+	}
+}
+
+// snapshot copies the cells of the grid, so a search can read them without holding the lock
+func (ng *NavGrid) snapshot() *NavGrid {
+	ng.lock.RLock()
+	defer ng.lock.RUnlock()
+
+	return &NavGrid{
+		width:    ng.width,
+		height:   ng.height,
+		walkable: slices.Clone(ng.walkable),
+		blocked:  slices.Clone(ng.blocked),
+		costs:    slices.Clone(ng.costs),
+		minCost:  ng.minCost,
+	}
+}
+
+func (ng *NavGrid) GetSize() (width, height int32) {
+	return ng.width, ng.height
+}
+
+func (ng *NavGrid) IsInside(col, row int32) bool {
+	return col >= 0 && row >= 0 && col < ng.width && row < ng.height
+}
+
+// IsWalkable reports whether the cell can be entered. Cells outside the grid are not walkable
+func (ng *NavGrid) IsWalkable(col, row int32) bool {
+	ng.lock.RLock()
+	defer ng.lock.RUnlock()
+
+	return ng.isWalkable(col, row)
+}
+
+func (ng *NavGrid) isWalkable(col, row int32) bool {
+	return ng.IsInside(col, row) && ng.walkable[row*ng.width+col] && !ng.blocked[row*ng.width+col]
+}
+
+// SetWalkable changes the walkability read from the tiles (e.g. when a door opens).
+// Safe to call while paths are being searched, the searches running keep the grid they started with
+func (ng *NavGrid) SetWalkable(col, row int32, walkable bool) {
+	if !ng.IsInside(col, row) {
+		return
+	}
+
+	ng.lock.Lock()
+	defer ng.lock.Unlock()
+
+	ng.walkable[row*ng.width+col] = walkable
+}
+
+// SetBlocked marks a cell as blocked by an obstacle (objects, units...), independently of its tiles.
+// Safe to call while paths are being searched, the searches running keep the grid they started with
+func (ng *NavGrid) SetBlocked(col, row int32, blocked bool) {
+	if !ng.IsInside(col, row) {
+		return
+	}
+
+	ng.lock.Lock()
+	defer ng.lock.Unlock()
+
+	ng.blocked[row*ng.width+col] = blocked
+}
+
+func (ng *NavGrid) GetCost(col, row int32) float32 {
+	ng.lock.RLock()
+	defer ng.lock.RUnlock()
+
+	if !ng.IsInside(col, row) {
+		return 0
+	}
+	return ng.costs[row*ng.width+col]
+}
+
+// SetCost changes the cost to enter a cell. Costs must be greater than 0
+func (ng *NavGrid) SetCost(col, row int32, cost float32) {
+	if !ng.IsInside(col, row) || cost <= 0 {
+		return
+	}
+
+	ng.lock.Lock()
+	defer ng.lock.Unlock()
+
+	ng.costs[row*ng.width+col] = cost
+	if cost < ng.minCost {
+		ng.minCost = cost
+	}
+}
+
+// UpdateFromMap reads the tiles of a cell again from the map, e.g. from a GameMap tile change listener.
+// Obstacles set with SetBlocked are kept
+func (ng *NavGrid) UpdateFromMap(gameMap *woutils.GameMap, col, row int32) {
+	if !ng.IsInside(col, row) {
+		return
+	}
+
+	walkable, cost := readCell(gameMap, col, row)
+
+	ng.lock.Lock()
+	defer ng.lock.Unlock()
+
+	ng.walkable[row*ng.width+col] = walkable
+	ng.costs[row*ng.width+col] = cost
+	if cost > 0 && cost < ng.minCost {
+		ng.minCost = cost
+	}
+}
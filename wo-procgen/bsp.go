@@ -0,0 +1,162 @@
+package woprocgen
+
+import "math/rand/v2"
+
+type Room struct {
+	Col    int32
+	Row    int32
+	Width  int32
+	Height int32
+}
+
+func (r Room) Center() (col, row int32) {
+	return r.Col + r.Width/2, r.Row + r.Height/2
+}
+
+type BSPOptions struct {
+	MinLeafSize int32 // Areas smaller than twice this size are not split. At least 1
+	MinRoomSize int32 // At least 1
+	RoomPadding int32 // Walls kept between the room and the border of its area
+}
+
+func NewBSPOptions() BSPOptions {
+	return BSPOptions{
+		MinLeafSize: 8,
+		MinRoomSize: 4,
+		RoomPadding: 1,
+	}
+}
+
+type bspLeaf struct {
+	room    Room
+	col     int32
+	row     int32
+	width   int32
+	height  int32
+	first   *bspLeaf
+	second  *bspLeaf
+	hasRoom bool
+}
+
+// GenerateRooms generates a dungeon of rooms and corridors with binary space partitioning:
+// the map is split in two areas again and again, a room is placed in each area,
+// and the rooms of each pair of areas are connected by a corridor.
+// Returns the grid (FLOOR and WALL) and the rooms
+func GenerateRooms(width, height int32, options BSPOptions, random *rand.Rand) (Grid, []Room) {
+	// Areas and rooms without size would be split forever
+	options.MinLeafSize = max(options.MinLeafSize, 1)
+	options.MinRoomSize = max(options.MinRoomSize, 1)
+	options.RoomPadding = max(options.RoomPadding, 0)
+
+	grid := NewGrid(width, height, WALL)
+	root := &bspLeaf{col: 0, row: 0, width: width, height: height}
+
+	splitLeaf(root, options, random)
+
+	var rooms []Room
+	placeRooms(root, &grid, &rooms, options, random)
+
+	return grid, rooms
+}
+
+func splitLeaf(leaf *bspLeaf, options BSPOptions, random *rand.Rand) {
+	canSplitHorizontally := leaf.height >= 2*options.MinLeafSize
+	canSplitVertically := leaf.width >= 2*options.MinLeafSize
+	if !canSplitHorizontally && !canSplitVertically {
+		return
+	}
+
+	// Splits across the longest side, so the areas don't get too thin
+	splitVertically := canSplitVertically
+	if canSplitHorizontally && canSplitVertically {
+		switch {
+		case leaf.width > leaf.height*5/4:
+			splitVertically = true
+		case leaf.height > leaf.width*5/4:
+			splitVertically = false
+		default:
+			splitVertically = random.IntN(2) == 0
+		}
+	}
+
+	size := leaf.height
+	if splitVertically {
+		size = leaf.width
+	}
+	// Both children get at least MinLeafSize (at least 1), so neither is empty
+	split := options.MinLeafSize + random.Int32N(size-2*options.MinLeafSize+1)
+
+	if splitVertically {
+		leaf.first = &bspLeaf{col: leaf.col, row: leaf.row, width: split, height: leaf.height}
+		leaf.second = &bspLeaf{col: leaf.col + split, row: leaf.row, width: leaf.width - split, height: leaf.height}
+	} else {
+		leaf.first = &bspLeaf{col: leaf.col, row: leaf.row, width: leaf.width, height: split}
+		leaf.second = &bspLeaf{col: leaf.col, row: leaf.row + split, width: leaf.width, height: leaf.height - split}
+	}
+
+	splitLeaf(leaf.first, options, random)
+	splitLeaf(leaf.second, options, random)
+}
+
+// placeRooms carves a room in each leaf and connects the rooms of sibling areas.
+// The room of an area that was split is any of the rooms inside it
+func placeRooms(leaf *bspLeaf, grid *Grid, rooms *[]Room, options BSPOptions, random *rand.Rand) {
+	if leaf.first == nil {
+		maxWidth := leaf.width - 2*options.RoomPadding
+		maxHeight := leaf.height - 2*options.RoomPadding
+		if maxWidth < options.MinRoomSize || maxHeight < options.MinRoomSize {
+			return
+		}
+
+		roomWidth := options.MinRoomSize + random.Int32N(maxWidth-options.MinRoomSize+1)
+		roomHeight := options.MinRoomSize + random.Int32N(maxHeight-options.MinRoomSize+1)
+		leaf.room = Room{
+			Col:    leaf.col + options.RoomPadding + random.Int32N(maxWidth-roomWidth+1),
+			Row:    leaf.row + options.RoomPadding + random.Int32N(maxHeight-roomHeight+1),
+			Width:  roomWidth,
+			Height: roomHeight,
+		}
+		leaf.hasRoom = true
+
+		grid.FillRect(leaf.room.Col, leaf.room.Row, leaf.room.Width, leaf.room.Height, FLOOR)
+		*rooms = append(*rooms, leaf.room)
+		return
+	}
+
+	placeRooms(leaf.first, grid, rooms, options, random)
+	placeRooms(leaf.second, grid, rooms, options, random)
+
+	switch {
+	case leaf.first.hasRoom && leaf.second.hasRoom:
+		carveCorridor(grid, leaf.first.room, leaf.second.room, random)
+		if random.IntN(2) == 0 {
+			leaf.room = leaf.first.room
+		} else {
+			leaf.room = leaf.second.room
+		}
+		leaf.hasRoom = true
+	case leaf.first.hasRoom:
+		leaf.room, leaf.hasRoom = leaf.first.room, true
+	case leaf.second.hasRoom:
+		leaf.room, leaf.hasRoom = leaf.second.room, true
+	}
+}
+
+// carveCorridor connects the centers of two rooms with an L shaped corridor
+func carveCorridor(grid *Grid, from, to Room, random *rand.Rand) {
+	fromCol, fromRow := from.Center()
+	toCol, toRow := to.Center()
+
+	if random.IntN(2) == 0 {
+		carveLine(grid, fromCol, fromRow, toCol, fromRow)
+		carveLine(grid, toCol, fromRow, toCol, toRow)
+	} else {
+		carveLine(grid, fromCol, fromRow, fromCol, toRow)
+		carveLine(grid, fromCol, toRow, toCol, toRow)
+	}
+}
+
+// carveLine sets the cells of a horizontal or vertical line to FLOOR
+func carveLine(grid *Grid, fromCol, fromRow, toCol, toRow int32) {
+	grid.FillRect(min(fromCol, toCol), min(fromRow, toRow), max(fromCol, toCol)-min(fromCol, toCol)+1, max(fromRow, toRow)-min(fromRow, toRow)+1, FLOOR)
+}
@@ -0,0 +1,54 @@
+package woprocgen
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGenerateRoomsIsDeterministic(t *testing.T) {
+	firstGrid, firstRooms := GenerateRooms(60, 40, NewBSPOptions(), NewRand(42))
+	secondGrid, secondRooms := GenerateRooms(60, 40, NewBSPOptions(), NewRand(42))
+
+	if !slices.Equal(firstGrid.Cells, secondGrid.Cells) || !slices.Equal(firstRooms, secondRooms) {
+		t.Fatal("the same seed generated different maps")
+	}
+	if len(firstRooms) < 2 {
+		t.Fatalf("generated %d rooms, expected at least 2", len(firstRooms))
+	}
+}
+
+func TestGenerateRooms(t *testing.T) {
+	tests := []struct {
+		name    string
+		width   int32
+		height  int32
+		options BSPOptions
+	}{
+		{"default options", 60, 40, NewBSPOptions()},
+		{"small leaves", 30, 30, BSPOptions{MinLeafSize: 3, MinRoomSize: 1, RoomPadding: 0}},
+		{"leaves without size", 12, 12, BSPOptions{MinLeafSize: 0, MinRoomSize: 0, RoomPadding: 0}},
+		{"negative sizes", 12, 12, BSPOptions{MinLeafSize: -4, MinRoomSize: -1, RoomPadding: -1}},
+		{"map smaller than a leaf", 5, 5, NewBSPOptions()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grid, rooms := GenerateRooms(test.width, test.height, test.options, NewRand(7))
+
+			for _, room := range rooms {
+				if room.Width < 1 || room.Height < 1 || room.Col < 0 || room.Row < 0 ||
+					room.Col+room.Width > test.width || room.Row+room.Height > test.height {
+					t.Fatalf("room %+v is empty or outside of the map", room)
+				}
+				if grid.Get(room.Col, room.Row, WALL) != FLOOR {
+					t.Fatalf("room %+v was not carved", room)
+				}
+			}
+
+			// The corridors connect every room
+			if regions := grid.Regions(FLOOR); len(regions) > 1 {
+				t.Fatalf("the floor has %d regions, expected 1", len(regions))
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package woprocgen
+
+import "math/rand/v2"
+
+type CaveOptions struct {
+	WallProbability   float64 // Chance of each cell starting as a wall
+	Iterations        int     // Smoothing steps. More steps make rounder caves
+	WallThreshold     int     // Cells with at least this many walls in the 3x3 square around them (including themselves) become walls
+	KeepLargestRegion bool    // Fills the caves not connected to the largest one, so every floor is reachable
+}
+
+func NewCaveOptions() CaveOptions {
+	return CaveOptions{
+		WallProbability:   0.45,
+		Iterations:        5,
+		WallThreshold:     5,
+		KeepLargestRegion: true,
+	}
+}
+
+// GenerateCaves generates caves with a cellular automaton: the cells start as random walls and floors,
+// and each step turns the cells surrounded by walls into walls, and the others into floors.
+// The border of the grid is always wall
+func GenerateCaves(width, height int32, options CaveOptions, random *rand.Rand) Grid {
+	grid := NewGrid(width, height, FLOOR)
+	for index := range grid.Cells {
+		if random.Float64() < options.WallProbability {
+			grid.Cells[index] = WALL
+		}
+	}
+
+	next := NewGrid(width, height, FLOOR)
+	for range options.Iterations {
+		for row := int32(0); row < height; row++ {
+			for col := int32(0); col < width; col++ {
+				walls := grid.CountNeighbors(col, row, WALL, WALL)
+				if grid.Get(col, row, WALL) == WALL {
+					walls++
+				}
+
+				if walls >= options.WallThreshold {
+					next.Set(col, row, WALL)
+				} else {
+					next.Set(col, row, FLOOR)
+				}
+			}
+		}
+		grid, next = next, grid
+	}
+
+	for row := int32(0); row < height; row++ {
+		for col := int32(0); col < width; col++ {
+			if col == 0 || row == 0 || col == width-1 || row == height-1 {
+				grid.Set(col, row, WALL)
+			}
+		}
+	}
+
+	if options.KeepLargestRegion {
+		keepLargestRegion(&grid, FLOOR, WALL)
+	}
+
+	return grid
+}
+
+// keepLargestRegion replaces every region of value, except the largest one, with fill
+func keepLargestRegion(grid *Grid, value int32, fill int32) {
+	regions := grid.Regions(value)
+
+	largest := -1
+	for index, region := range regions {
+		if largest < 0 || len(region) > len(regions[largest]) {
+			largest = index
+		}
+	}
+
+	for index, region := range regions {
+		if index == largest {
+			continue
+		}
+		for _, cell := range region {
+			grid.Cells[cell] = fill
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package woprocgen
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGenerateCaves(t *testing.T) {
+	tests := []struct {
+		name    string
+		seed    uint64
+		options CaveOptions
+	}{
+		{"default options", 1, NewCaveOptions()},
+		{"other seed", 99, NewCaveOptions()},
+		{"without smoothing", 3, CaveOptions{WallProbability: 0.4, Iterations: 0, WallThreshold: 5, KeepLargestRegion: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grid := GenerateCaves(40, 30, test.options, NewRand(test.seed))
+			again := GenerateCaves(40, 30, test.options, NewRand(test.seed))
+			if !slices.Equal(grid.Cells, again.Cells) {
+				t.Fatal("the same seed generated different caves")
+			}
+
+			for row := int32(0); row < grid.Height; row++ {
+				for col := int32(0); col < grid.Width; col++ {
+					isBorder := col == 0 || row == 0 || col == grid.Width-1 || row == grid.Height-1
+					if isBorder && grid.Get(col, row, FLOOR) != WALL {
+						t.Fatalf("border cell %d,%d is not a wall", col, row)
+					}
+				}
+			}
+
+			if regions := grid.Regions(FLOOR); len(regions) > 1 {
+				t.Fatalf("the floor has %d regions, expected 1", len(regions))
+			}
+		})
+	}
+}
+
+func TestGenerateCavesKeepsSmallRegions(t *testing.T) {
+	options := NewCaveOptions()
+	options.KeepLargestRegion = false
+
+	grid := GenerateCaves(40, 30, options, NewRand(5))
+	kept := GenerateCaves(40, 30, NewCaveOptions(), NewRand(5))
+
+	floors, keptFloors := 0, 0
+	for index := range grid.Cells {
+		if grid.Cells[index] == FLOOR {
+			floors++
+		}
+		if kept.Cells[index] == FLOOR {
+			keptFloors++
+			if grid.Cells[index] != FLOOR {
+				t.Fatalf("cell %d is a floor only when the largest region is kept", index)
+			}
+		}
+	}
+	if keptFloors > floors {
+		t.Fatalf("keeping the largest region added floors: %d > %d", keptFloors, floors)
+	}
+}
@@ -0,0 +1,126 @@
+// Package woprocgen generates maps procedurally: caves, rooms and corridors, and terrain.
+//
+// The generators fill a Grid of values (e.g. FLOOR and WALL), which is turned into tiles
+// with Grid.ToTiles and placed in a woutils.MapBuilder layer. Every generator takes its own
+// random number generator, so the same seed always generates the same map.
+package woprocgen
+
+import "math/rand/v2"
+
+const (
+	FLOOR int32 = 0
+	WALL  int32 = 1
+)
+
+// NewRand creates a random number generator for the generators, always giving the same numbers for the same seed
+func NewRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9E3779B97F4A7C15))
+}
+
+// Grid is a map of values, one for each tile
+type Grid struct {
+	Width  int32
+	Height int32
+	Cells  []int32 // Values row by row
+}
+
+func NewGrid(width, height int32, value int32) Grid {
+	grid := Grid{
+		Width:  width,
+		Height: height,
+		Cells:  make([]int32, width*height),
+	}
+	grid.Fill(value)
+	return grid
+}
+
+func (g *Grid) IsInside(col, row int32) bool {
+	return col >= 0 && row >= 0 && col < g.Width && row < g.Height
+}
+
+// Get returns the value of the cell, or outside for cells outside the grid
+func (g *Grid) Get(col, row int32, outside int32) int32 {
+	if !g.IsInside(col, row) {
+		return outside
+	}
+	return g.Cells[row*g.Width+col]
+}
+
+func (g *Grid) Set(col, row int32, value int32) {
+	if g.IsInside(col, row) {
+		g.Cells[row*g.Width+col] = value
+	}
+}
+
+func (g *Grid) Fill(value int32) {
+	for index := range g.Cells {
+		g.Cells[index] = value
+	}
+}
+
+// FillRect sets the cells of the rectangle (clipped to the grid) to value
+func (g *Grid) FillRect(col, row, width, height int32, value int32) {
+	for rectRow := row; rectRow < row+height; rectRow++ {
+		for rectCol := col; rectCol < col+width; rectCol++ {
+			g.Set(rectCol, rectRow, value)
+		}
+	}
+}
+
+// CountNeighbors counts the 8 cells around the cell with value. Cells outside the grid count as outside
+func (g *Grid) CountNeighbors(col, row int32, value int32, outside int32) int {
+	count := 0
+	for neighborRow := row - 1; neighborRow <= row+1; neighborRow++ {
+		for neighborCol := col - 1; neighborCol <= col+1; neighborCol++ {
+			if (neighborCol != col || neighborRow != row) && g.Get(neighborCol, neighborRow, outside) == value {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Regions returns the groups of connected (horizontally or vertically) cells with value,
+// each one as the list of its cell indexes
+func (g *Grid) Regions(value int32) [][]int32 {
+	visited := make([]bool, len(g.Cells))
+	var regions [][]int32
+
+	for start := range g.Cells {
+		if visited[start] || g.Cells[start] != value {
+			continue
+		}
+
+		visited[start] = true
+		region := []int32{int32(start)}
+		for next := 0; next < len(region); next++ {
+			col, row := region[next]%g.Width, region[next]/g.Width
+
+			for _, offset := range [4][2]int32{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				neighborCol, neighborRow := col+offset[0], row+offset[1]
+				if !g.IsInside(neighborCol, neighborRow) {
+					continue
+				}
+
+				neighbor := neighborRow*g.Width + neighborCol
+				if !visited[neighbor] && g.Cells[neighbor] == value {
+					visited[neighbor] = true
+					region = append(region, neighbor)
+				}
+			}
+		}
+
+		regions = append(regions, region)
+	}
+
+	return regions
+}
+
+// ToTiles converts the values to tile GIDs, for woutils.MapBuilder.SetLayerTiles
+func (g *Grid) ToTiles(gidOf func(value int32) uint32) []uint32 {
+	tiles := make([]uint32, len(g.Cells))
+	for index, value := range g.Cells {
+		tiles[index] = gidOf(value)
+	}
+	return tiles
+}
@@ -0,0 +1,103 @@
+package woprocgen
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+type NoiseOptions struct {
+	Scale       float64 // Size, in tiles, of the biggest features
+	Octaves     int     // Layers of detail added over the biggest features
+	Persistence float64 // How much each octave weights compared to the previous one
+}
+
+func NewNoiseOptions() NoiseOptions {
+	return NoiseOptions{
+		Scale:       16,
+		Octaves:     4,
+		Persistence: 0.5,
+	}
+}
+
+// NoiseMap has a value between 0 and 1 for each tile
+type NoiseMap struct {
+	Width  int32
+	Height int32
+	Values []float64
+}
+
+func (nm *NoiseMap) Get(col, row int32) float64 {
+	return nm.Values[row*nm.Width+col]
+}
+
+// Classify turns the noise into a grid of terrains: tiles below thresholds[0] get 0 (e.g. water),
+// below thresholds[1] get 1 (e.g. sand), and so on. Thresholds must be in increasing order
+func (nm *NoiseMap) Classify(thresholds []float64) Grid {
+	grid := NewGrid(nm.Width, nm.Height, 0)
+	for index, value := range nm.Values {
+		terrain := int32(len(thresholds))
+		for thresholdIndex, threshold := range thresholds {
+			if value < threshold {
+				terrain = int32(thresholdIndex)
+				break
+			}
+		}
+		grid.Cells[index] = terrain
+	}
+	return grid
+}
+
+// GenerateNoise generates smooth random values (fractal value noise), for terrain like height maps,
+// where close tiles have close values
+func GenerateNoise(width, height int32, options NoiseOptions, random *rand.Rand) NoiseMap {
+	noiseMap := NoiseMap{
+		Width:  width,
+		Height: height,
+		Values: make([]float64, width*height),
+	}
+
+	totalWeight := 0.0
+	weight := 1.0
+	scale := math.Max(options.Scale, 1)
+
+	for range max(options.Octaves, 1) {
+		// Random values at the corners of a lattice of scale tiles, interpolated between them
+		latticeWidth := int(float64(width)/scale) + 2
+		latticeHeight := int(float64(height)/scale) + 2
+		lattice := make([]float64, latticeWidth*latticeHeight)
+		for index := range lattice {
+			lattice[index] = random.Float64()
+		}
+
+		for row := int32(0); row < height; row++ {
+			for col := int32(0); col < width; col++ {
+				x, y := float64(col)/scale, float64(row)/scale
+				latticeX, latticeY := int(x), int(y)
+				fractionX, fractionY := smoothStep(x-float64(latticeX)), smoothStep(y-float64(latticeY))
+
+				topLeft := lattice[latticeY*latticeWidth+latticeX]
+				topRight := lattice[latticeY*latticeWidth+latticeX+1]
+				bottomLeft := lattice[(latticeY+1)*latticeWidth+latticeX]
+				bottomRight := lattice[(latticeY+1)*latticeWidth+latticeX+1]
+
+				top := topLeft + (topRight-topLeft)*fractionX
+				bottom := bottomLeft + (bottomRight-bottomLeft)*fractionX
+				noiseMap.Values[row*width+col] += (top + (bottom-top)*fractionY) * weight
+			}
+		}
+
+		totalWeight += weight
+		weight *= options.Persistence
+		scale = math.Max(scale/2, 1)
+	}
+
+	for index := range noiseMap.Values {
+		noiseMap.Values[index] /= totalWeight
+	}
+
+	return noiseMap
+}
+
+func smoothStep(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
@@ -0,0 +1,251 @@
+package woutils
+
+import (
+	"log"
+
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// AnimatedSprite plays the clips of a SpriteSheet. The rect (RectMixin) is where the full frame is drawn.
+// By default the animation advances with the real time between renders. Use SetAutoUpdate(false)
+// to advance it with Update instead (e.g. to pause it with the game).
+type AnimatedSprite struct {
+	womixins.HideMixin
+	womixins.RectMixin
+	spriteSheet  *SpriteSheet
+	clip         *AnimationClip
+	frameIndex   int
+	direction    int     // 1 forward, -1 backward (ping-pong)
+	elapsed      float64 // Milliseconds the current frame has been shown
+	speed        float64
+	isPlaying    bool
+	isFinished   bool
+	autoUpdate   bool
+	lastTicks    uint64
+	flip         sdl.RendererFlip
+	onFrameEvent func(event string)
+	onFinished   func(clipName string)
+}
+
+func NewAnimatedSprite(spriteSheet *SpriteSheet) AnimatedSprite {
+	return AnimatedSprite{
+		HideMixin:    womixins.NewHideMixin(),
+		RectMixin:    womixins.NewRectMixin(0, 0, 0, 0),
+		spriteSheet:  spriteSheet,
+		clip:         nil,
+		frameIndex:   0,
+		direction:    1,
+		elapsed:      0,
+		speed:        1,
+		isPlaying:    false,
+		isFinished:   false,
+		autoUpdate:   true,
+		lastTicks:    0,
+		flip:         sdl.FLIP_NONE,
+		onFrameEvent: nil,
+		onFinished:   nil,
+	}
+}
+
+// Play starts the clip named clipName from its first frame. Playing the clip already
+// playing does nothing, so it can be called every frame (e.g. Play("walk") while walking).
+// The sprite takes the size of the first frame if it has no size yet
+func (as *AnimatedSprite) Play(clipName string) {
+	clip := as.spriteSheet.GetClip(clipName)
+	if clip == nil {
+		log.Fatalf("Sprite sheet has no clip named %s", clipName)
+	}
+
+	if clip == as.clip && as.isPlaying {
+		return
+	}
+
+	as.clip = clip
+	as.Restart()
+
+	if !as.HasArea() && len(clip.Frames) > 0 {
+		as.SetSize(clip.Frames[0].SourceWidth, clip.Frames[0].SourceHeight)
+	}
+}
+
+// Restart plays the current clip again from its first frame
+func (as *AnimatedSprite) Restart() {
+	if as.clip == nil {
+		return
+	}
+
+	as.frameIndex = 0
+	as.direction = 1
+	as.elapsed = 0
+	as.isPlaying = len(as.clip.Frames) > 0
+	as.isFinished = false
+	as.fireFrameEvents()
+}
+
+// changeClip switches to another clip keeping the progress (e.g. the same walk, facing another direction)
+func (as *AnimatedSprite) changeClip(clip *AnimationClip) {
+	if clip == as.clip {
+		return
+	}
+
+	if as.clip == nil || len(clip.Frames) == 0 {
+		as.clip = clip
+		as.Restart()
+		return
+	}
+
+	as.clip = clip
+	as.frameIndex = min(as.frameIndex, len(clip.Frames)-1)
+}
+
+func (as *AnimatedSprite) Pause() {
+	as.isPlaying = false
+}
+
+func (as *AnimatedSprite) Resume() {
+	as.isPlaying = as.clip != nil && !as.isFinished
+}
+
+func (as *AnimatedSprite) IsPlaying() bool {
+	return as.isPlaying
+}
+
+// IsFinished reports whether a clip played once (AnimationOnce) reached its end
+func (as *AnimatedSprite) IsFinished() bool {
+	return as.isFinished
+}
+
+// GetClipName returns the name of the current clip, or "" if no clip was played
+func (as *AnimatedSprite) GetClipName() string {
+	if as.clip == nil {
+		return ""
+	}
+	return as.clip.Name
+}
+
+func (as *AnimatedSprite) GetFrameIndex() int {
+	return as.frameIndex
+}
+
+// SetSpeed changes how fast the clips are played. 1 is the normal speed, 2 is twice as fast
+func (as *AnimatedSprite) SetSpeed(speed float64) {
+	as.speed = max(speed, 0)
+}
+
+func (as *AnimatedSprite) SetAutoUpdate(autoUpdate bool) {
+	as.autoUpdate = autoUpdate
+	as.lastTicks = 0
+}
+
+// SetFlip mirrors the frames (sdl.FLIP_HORIZONTAL and/or sdl.FLIP_VERTICAL)
+func (as *AnimatedSprite) SetFlip(flip sdl.RendererFlip) {
+	as.flip = flip
+}
+
+func (as *AnimatedSprite) GetFlip() sdl.RendererFlip {
+	return as.flip
+}
+
+// OnFrameEvent sets the function called with the events of each frame shown (see AnimationClip.AddFrameEvent)
+func (as *AnimatedSprite) OnFrameEvent(onFrameEvent func(event string)) {
+	as.onFrameEvent = onFrameEvent
+}
+
+// OnFinished sets the function called when a clip played once (AnimationOnce) reaches its end
+func (as *AnimatedSprite) OnFinished(onFinished func(clipName string)) {
+	as.onFinished = onFinished
+}
+
+// Update advances the animation by deltaMs milliseconds
+func (as *AnimatedSprite) Update(deltaMs uint64) {
+	if !as.isPlaying {
+		return
+	}
+
+	as.elapsed += float64(deltaMs) * as.speed
+	for as.isPlaying {
+		duration := float64(max(as.clip.Frames[as.frameIndex].Duration, 1))
+		if as.elapsed < duration {
+			return
+		}
+
+		as.elapsed -= duration
+		as.nextFrame()
+	}
+}
+
+func (as *AnimatedSprite) nextFrame() {
+	frameCount := len(as.clip.Frames)
+	next := as.frameIndex + as.direction
+
+	if next < 0 || next >= frameCount {
+		switch as.clip.Mode {
+		case AnimationLoop:
+			next = 0
+		case AnimationPingPong:
+			as.direction = -as.direction
+			next = max(min(as.frameIndex+as.direction, frameCount-1), 0)
+		case AnimationOnce:
+			as.isPlaying = false
+			as.isFinished = true
+			as.elapsed = 0
+			if as.onFinished != nil {
+				as.onFinished(as.clip.Name)
+			}
+			return
+		}
+	}
+
+	as.frameIndex = next
+	as.fireFrameEvents()
+}
+
+func (as *AnimatedSprite) fireFrameEvents() {
+	if as.onFrameEvent == nil || as.clip == nil || len(as.clip.Frames) == 0 {
+		return
+	}
+
+	for _, event := range as.clip.Frames[as.frameIndex].Events {
+		as.onFrameEvent(event)
+	}
+}
+
+func (as *AnimatedSprite) Render(context *GameContext) {
+	if as.autoUpdate {
+		ticks := sdl.GetTicks64()
+		if as.lastTicks != 0 {
+			as.Update(ticks - as.lastTicks)
+		}
+		as.lastTicks = ticks
+	}
+
+	if as.clip == nil || len(as.clip.Frames) == 0 || !as.HasArea() {
+		return
+	}
+
+	// The frame may be trimmed, so it is placed inside the full frame, scaled to the sprite size
+	frame := &as.clip.Frames[as.frameIndex]
+	offsetX, offsetY := frame.OffsetX, frame.OffsetY
+	if as.flip&sdl.FLIP_HORIZONTAL != 0 {
+		offsetX = frame.SourceWidth - frame.OffsetX - frame.SrcRect.W
+	}
+	if as.flip&sdl.FLIP_VERTICAL != 0 {
+		offsetY = frame.SourceHeight - frame.OffsetY - frame.SrcRect.H
+	}
+
+	scaleX := float32(as.W) / float32(max(frame.SourceWidth, 1))
+	scaleY := float32(as.H) / float32(max(frame.SourceHeight, 1))
+	destRect := sdl.Rect{
+		X: as.X + int32(float32(offsetX)*scaleX),
+		Y: as.Y + int32(float32(offsetY)*scaleY),
+		W: int32(float32(frame.SrcRect.W) * scaleX),
+		H: int32(float32(frame.SrcRect.H) * scaleY),
+	}
+
+	if as.flip == sdl.FLIP_NONE {
+		context.GetRenderer().Copy(as.spriteSheet.texture, &frame.SrcRect, &destRect)
+	} else {
+		context.GetRenderer().CopyEx(as.spriteSheet.texture, &frame.SrcRect, &destRect, 0, nil, as.flip)
+	}
+}
@@ -0,0 +1,389 @@
+package woutils
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"runtime"
+	"time"
+
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// assetJob loads one asset: decode runs in a worker goroutine (reading files and decoding images and audio),
+// finish runs in the main thread (creating textures and adding the asset to the AssetManager)
+type assetJob struct {
+	name    string
+	decode  func() error
+	finish  func() error
+	discard func() // Frees what decode created, when the loader is destroyed before finish
+}
+
+type assetJobResult struct {
+	job *assetJob
+	err error
+}
+
+// AssetLoader loads textures, fonts, audio chunks and maps in the background, so the window keeps
+// responding (e.g. to draw a loading screen). Files are read and decoded by worker goroutines, and the
+// textures are created in the main thread, a few each frame, by Update. Add the loader to the render
+// queue (it draws nothing) or call Update every frame.
+//
+// The loaded assets are kept in the AssetManager of the context, so creating the objects that use them
+// (e.g. NewImageFromFs or GetGameMap) does not load them again. Call Release after creating the objects.
+type AssetLoader struct {
+	womixins.HideMixin
+	context     *GameContext
+	fsys        fs.FS
+	requested   map[assetKey]bool // Avoids loading an asset twice
+	pending     []*assetJob       // Added before Start
+	results     chan assetJobResult
+	workers     chan struct{} // Limits the jobs decoding at the same time
+	done        chan struct{} // Closed by Destroy, to stop the workers
+	isStarted   bool
+	isDestroyed bool
+	running     int // Jobs started and not finished
+	loaded      int
+	total       int
+	frameBudget time.Duration // Time Update may spend finishing jobs each frame
+	textures    []*sdl.Texture
+	fonts       []*ttf.Font
+	chunks      []*mix.Chunk
+	tiledMaps   map[string]TiledMap
+	errors      []error
+	onProgress  func(loaded, total int)
+	onComplete  func()
+}
+
+// NewAssetLoader creates a loader of the files of fsys (e.g. a VirtualFS or an embed.FS)
+func NewAssetLoader(context *GameContext, fsys fs.FS) *AssetLoader {
+	return &AssetLoader{
+		HideMixin:   womixins.NewHideMixin(),
+		context:     context,
+		fsys:        fsys,
+		requested:   make(map[assetKey]bool),
+		pending:     nil,
+		results:     make(chan assetJobResult),
+		workers:     make(chan struct{}, min(runtime.NumCPU(), 4)),
+		done:        make(chan struct{}),
+		isStarted:   false,
+		isDestroyed: false,
+		running:     0,
+		loaded:      0,
+		total:       0,
+		frameBudget: 8 * time.Millisecond,
+		tiledMaps:   make(map[string]TiledMap),
+		onProgress:  nil,
+		onComplete:  nil,
+	}
+}
+
+// SetFrameBudget sets how long Update may spend creating textures each frame
+func (al *AssetLoader) SetFrameBudget(frameBudget time.Duration) {
+	al.frameBudget = frameBudget
+}
+
+// OnProgress sets the function called each time an asset is loaded. The total grows when the
+// maps are loaded, because their tilesets are only known then
+func (al *AssetLoader) OnProgress(onProgress func(loaded, total int)) {
+	al.onProgress = onProgress
+}
+
+// OnComplete sets the function called when all the assets are loaded (or failed to load, see GetErrors)
+func (al *AssetLoader) OnComplete(onComplete func()) {
+	al.onComplete = onComplete
+}
+
+func (al *AssetLoader) AddTexture(path string) {
+	al.addTexture(al.fsys, path)
+}
+
+func (al *AssetLoader) addTexture(fsys fs.FS, path string) {
+	key := newAssetKey(textureAsset, fsys, path, 0)
+	if al.request(key) {
+		return
+	}
+
+	var surface *sdl.Surface
+	al.add(&assetJob{
+		name: path,
+		decode: func() (err error) {
+			surface, err = LoadSurfaceFromFs(fsys, path)
+			return err
+		},
+		finish: func() error {
+			defer surface.Free()
+			texture, err := al.context.GetAssets().acquireTexture(key, func() (*sdl.Texture, error) {
+				return al.context.GetRenderer().CreateTextureFromSurface(surface)
+			})
+			if err == nil {
+				al.textures = append(al.textures, texture)
+			}
+			return err
+		},
+		discard: func() {
+			surface.Free()
+		},
+	})
+}
+
+func (al *AssetLoader) AddFont(path string, size int) {
+	key := newAssetKey(fontAsset, al.fsys, path, size)
+	if al.request(key) {
+		return
+	}
+
+	var data []byte
+	fsys := al.fsys
+	al.add(&assetJob{
+		name: path,
+		decode: func() (err error) {
+			data, err = fs.ReadFile(fsys, path)
+			return err
+		},
+		finish: func() error {
+			// Fonts are opened in the main thread, SDL_ttf is not thread-safe
+			font, err := al.context.GetAssets().acquireFont(key, func() ([]byte, error) {
+				return data, nil
+			})
+			if err == nil {
+				al.fonts = append(al.fonts, font)
+			}
+			return err
+		},
+		discard: func() {},
+	})
+}
+
+func (al *AssetLoader) AddChunk(path string) {
+	key := newAssetKey(chunkAsset, al.fsys, path, 0)
+	if al.request(key) {
+		return
+	}
+
+	var decoded *mix.Chunk
+	fsys := al.fsys
+	al.add(&assetJob{
+		name: path,
+		decode: func() (err error) {
+			decoded, err = loadChunkFromFs(fsys, path)
+			return err
+		},
+		finish: func() error {
+			chunk, err := al.context.GetAssets().acquireChunk(key, func() (*mix.Chunk, error) {
+				return decoded, nil
+			})
+			if chunk != decoded {
+				decoded.Free() // Loaded by someone else meanwhile
+			}
+			if err == nil {
+				al.chunks = append(al.chunks, chunk)
+			}
+			return err
+		},
+		discard: func() {
+			decoded.Free()
+		},
+	})
+}
+
+// AddGameMap loads a TMX file, its tilesets and their images. Get the map with GetGameMap when the loading completes
+func (al *AssetLoader) AddGameMap(tmxPath string) {
+	if _, exists := al.tiledMaps[tmxPath]; exists {
+		return
+	}
+
+	var tiledMap TiledMap
+	fsys := al.fsys
+	al.add(&assetJob{
+		name: tmxPath,
+		decode: func() (err error) {
+			tiledMap, err = loadTiledMap(fsys, tmxPath)
+			return err
+		},
+		finish: func() error {
+			al.tiledMaps[tmxPath] = tiledMap
+			for _, tileSet := range tiledMap.TmxMap.TileSets {
+				if tileSet.TsxData != nil && tileSet.TsxData.Image.Source != "" {
+					al.addTexture(fsys, ResolvePath(tileSet.TsxPath, tileSet.TsxData.Image.Source))
+				}
+			}
+			return nil
+		},
+		discard: func() {},
+	})
+}
+
+// request marks the asset as requested. Returns true if it was already requested or loaded,
+// in which case the loader just takes a reference to it
+func (al *AssetLoader) request(key assetKey) bool {
+	if al.requested[key] {
+		return true
+	}
+	al.requested[key] = true
+
+	assets := al.context.GetAssets()
+	if !assets.isLoaded(key) {
+		return false
+	}
+
+	entry, _ := assets.acquire(key, nil)
+	switch asset := entry.asset.(type) {
+	case *sdl.Texture:
+		al.textures = append(al.textures, asset)
+	case *ttf.Font:
+		al.fonts = append(al.fonts, asset)
+	case *mix.Chunk:
+		al.chunks = append(al.chunks, asset)
+	}
+	return true
+}
+
+func (al *AssetLoader) add(job *assetJob) {
+	al.total++
+	if al.isStarted {
+		al.run(job)
+	} else {
+		al.pending = append(al.pending, job)
+	}
+}
+
+// Start begins loading the assets added. Assets added after Start are loaded right away
+func (al *AssetLoader) Start() {
+	if al.isStarted {
+		return
+	}
+
+	al.isStarted = true
+	for _, job := range al.pending {
+		al.run(job)
+	}
+	al.pending = nil
+	al.checkComplete()
+}
+
+func (al *AssetLoader) run(job *assetJob) {
+	al.running++
+	go func() {
+		select {
+		case al.workers <- struct{}{}:
+		case <-al.done:
+			return
+		}
+		err := job.decode()
+		<-al.workers
+
+		select {
+		case al.results <- assetJobResult{job: job, err: err}:
+		case <-al.done:
+			if err == nil {
+				job.discard()
+			}
+		}
+	}()
+}
+
+// Update finishes the jobs decoded by the workers, creating their textures. It must run in the main thread
+func (al *AssetLoader) Update() {
+	if !al.isStarted || al.isDestroyed {
+		return
+	}
+
+	deadline := time.Now().Add(al.frameBudget)
+	for al.running > 0 && time.Now().Before(deadline) {
+		select {
+		case result := <-al.results:
+			al.finish(result)
+		default:
+			al.checkComplete()
+			return
+		}
+	}
+	al.checkComplete()
+}
+
+func (al *AssetLoader) finish(result assetJobResult) {
+	al.running--
+
+	err := result.err
+	if err == nil {
+		err = result.job.finish()
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to load %s: %w", result.job.name, err)
+		log.Println(err)
+		al.errors = append(al.errors, err)
+	}
+
+	al.loaded++
+	if al.onProgress != nil {
+		al.onProgress(al.loaded, al.total)
+	}
+}
+
+func (al *AssetLoader) checkComplete() {
+	if al.running == 0 && al.loaded == al.total && al.onComplete != nil {
+		onComplete := al.onComplete
+		al.onComplete = nil // Called once
+		onComplete()
+	}
+}
+
+// Render updates the loader, so it can be added to the render queue. It draws nothing
+func (al *AssetLoader) Render(context *GameContext) {
+	al.Update()
+}
+
+// GetProgress returns the fraction of the assets loaded, from 0 to 1
+func (al *AssetLoader) GetProgress() float64 {
+	if al.total == 0 {
+		return 1
+	}
+	return float64(al.loaded) / float64(al.total)
+}
+
+func (al *AssetLoader) IsComplete() bool {
+	return al.isStarted && al.running == 0 && al.loaded == al.total
+}
+
+// GetErrors returns the errors of the assets that failed to load
+func (al *AssetLoader) GetErrors() []error {
+	return al.errors
+}
+
+// GetGameMap creates the map loaded with AddGameMap. Its textures are already loaded
+func (al *AssetLoader) GetGameMap(tmxPath string) GameMap {
+	tiledMap, exists := al.tiledMaps[tmxPath]
+	if !exists {
+		log.Fatalf("Map %s was not loaded. Add it with AddGameMap and wait for the loading to complete", tmxPath)
+	}
+	return newGameMapFromTiledMap(al.context, tiledMap)
+}
+
+// Release releases the assets kept by the loader. The assets used by other objects are kept
+func (al *AssetLoader) Release() {
+	assets := al.context.GetAssets()
+	for _, texture := range al.textures {
+		assets.ReleaseTexture(texture)
+	}
+	for _, font := range al.fonts {
+		assets.ReleaseFont(font)
+	}
+	for _, chunk := range al.chunks {
+		assets.ReleaseChunk(chunk)
+	}
+	al.textures, al.fonts, al.chunks = nil, nil, nil
+	clear(al.requested)
+}
+
+// Destroy stops the loading and releases the assets kept by the loader
+func (al *AssetLoader) Destroy() {
+	if !al.isDestroyed {
+		al.isDestroyed = true
+		close(al.done)
+	}
+	al.Release()
+}
@@ -0,0 +1,347 @@
+package woutils
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"reflect"
+
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+type assetKind uint8
+
+const (
+	textureAsset assetKind = iota
+	fontAsset
+	chunkAsset
+)
+
+type assetKey struct {
+	kind assetKind
+	fsys any // Identifies the fs.FS of path (see fsKey)
+	path string
+	size int // Font size
+}
+
+type assetEntry struct {
+	key        assetKey
+	asset      any // *sdl.Texture, *ttf.Font or *mix.Chunk
+	references int
+	bytes      int64      // Estimated memory used by textures
+	rwops      *sdl.RWops // Keeps the data of fonts open
+	data       []byte
+}
+
+// AssetStats is a snapshot of the assets loaded by an AssetManager
+type AssetStats struct {
+	Textures     int   // Textures loaded
+	Fonts        int   // Fonts loaded
+	Chunks       int   // Audio chunks loaded
+	References   int   // Users of all loaded assets
+	TextureBytes int64 // Estimated memory used by the loaded textures
+	Loads        uint64
+	Hits         uint64 // Acquires served by an asset already loaded
+	Frees        uint64
+}
+
+func (as AssetStats) String() string {
+	return fmt.Sprintf("%d textures (%.1f MB), %d fonts, %d chunks, %d references. %d loads, %d hits, %d frees",
+		as.Textures, float64(as.TextureBytes)/(1024*1024), as.Fonts, as.Chunks, as.References, as.Loads, as.Hits, as.Frees)
+}
+
+// AssetManager shares the textures, fonts and audio chunks loaded from the same path (and options, like
+// the font size). Every Acquire must be matched by a Release of the asset; the asset is freed when
+// the last user releases it. Shared assets must not be destroyed by their users, nor changed
+// (e.g. texture.SetAlphaMod) other than while drawing them, like Image does.
+// Use context.GetAssets() to get the manager of a GameContext.
+type AssetManager struct {
+	renderer *sdl.Renderer
+	entries  map[assetKey]*assetEntry
+	byAsset  map[any]*assetEntry
+	stats    AssetStats
+}
+
+func NewAssetManager() *AssetManager {
+	return &AssetManager{
+		renderer: nil,
+		entries:  make(map[assetKey]*assetEntry),
+		byAsset:  make(map[any]*assetEntry),
+		stats:    AssetStats{},
+	}
+}
+
+// SetRenderer sets the renderer of the textures (GameContext.Start does it)
+func (am *AssetManager) SetRenderer(renderer *sdl.Renderer) {
+	am.renderer = renderer
+}
+
+func newAssetKey(kind assetKind, fsys fs.FS, path string, size int) assetKey {
+	return assetKey{kind: kind, fsys: fsKey(fsys), path: path, size: size}
+}
+
+// fsKey returns a comparable value identifying fsys. Most file systems are comparable (e.g. embed.FS or
+// *zip.Reader) and map based ones (e.g. fstest.MapFS) are identified by their map. The others (e.g. structs
+// holding slices or maps) get a new key each time, so their assets are not shared
+func fsKey(fsys fs.FS) any {
+	value := reflect.ValueOf(fsys)
+	if value.Comparable() {
+		return fsys
+	}
+	if value.Kind() == reflect.Map {
+		return mapFsKey{fsType: value.Type(), pointer: value.Pointer()}
+	}
+	return new(int)
+}
+
+type mapFsKey struct {
+	fsType  reflect.Type
+	pointer uintptr
+}
+
+// acquire returns the asset with key, loading it with load if it is not loaded yet
+func (am *AssetManager) acquire(key assetKey, load func(entry *assetEntry) error) (*assetEntry, error) {
+	if entry, exists := am.entries[key]; exists {
+		entry.references++
+		am.stats.Hits++
+		return entry, nil
+	}
+
+	entry := &assetEntry{key: key, references: 1}
+	if err := load(entry); err != nil {
+		return nil, err
+	}
+
+	am.entries[key] = entry
+	am.byAsset[entry.asset] = entry
+	am.stats.Loads++
+	return entry, nil
+}
+
+// AcquireTexture returns the texture of the image at path, loading it on the first use
+func (am *AssetManager) AcquireTexture(path string) (*sdl.Texture, error) {
+	return am.AcquireTextureFromFs(osFS, path)
+}
+
+// AcquireTextureFromFs returns the texture of the image at path in fsys, loading it on the first use
+func (am *AssetManager) AcquireTextureFromFs(fsys fs.FS, path string) (*sdl.Texture, error) {
+	return am.acquireTexture(newAssetKey(textureAsset, fsys, path, 0), func() (*sdl.Texture, error) {
+		return LoadTextureFromFs(am.renderer, fsys, path)
+	})
+}
+
+func (am *AssetManager) acquireTexture(key assetKey, load func() (*sdl.Texture, error)) (*sdl.Texture, error) {
+	if am.renderer == nil {
+		log.Fatalf("Cannot load texture %s without a renderer. Did you run Start()?", key.path)
+	}
+
+	entry, err := am.acquire(key, func(entry *assetEntry) error {
+		texture, err := load()
+		if err != nil {
+			return err
+		}
+
+		if _, _, width, height, err := texture.Query(); err == nil {
+			entry.bytes = int64(width) * int64(height) * 4
+		}
+		entry.asset = texture
+		am.stats.TextureBytes += entry.bytes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.asset.(*sdl.Texture), nil
+}
+
+// AcquireFont returns the font at path with size points, loading it on the first use
+func (am *AssetManager) AcquireFont(path string, size int) (*ttf.Font, error) {
+	return am.AcquireFontFromFs(osFS, path, size)
+}
+
+// AcquireFontFromFs returns the font at path in fsys with size points, loading it on the first use.
+// The font data is kept in memory until the font is freed
+func (am *AssetManager) AcquireFontFromFs(fsys fs.FS, path string, size int) (*ttf.Font, error) {
+	return am.acquireFont(newAssetKey(fontAsset, fsys, path, size), func() ([]byte, error) {
+		return fs.ReadFile(fsys, path)
+	})
+}
+
+func (am *AssetManager) acquireFont(key assetKey, read func() ([]byte, error)) (*ttf.Font, error) {
+	entry, err := am.acquire(key, func(entry *assetEntry) error {
+		data, err := read()
+		if err != nil {
+			return err
+		}
+
+		rwops, err := sdl.RWFromMem(data)
+		if err != nil {
+			return err
+		}
+
+		font, err := ttf.OpenFontRW(rwops, 0, key.size)
+		if err != nil {
+			rwops.Close()
+			return err
+		}
+
+		entry.asset = font
+		entry.rwops = rwops
+		entry.data = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.asset.(*ttf.Font), nil
+}
+
+// AcquireDefaultFont returns the font embedded in the engine with size points
+func (am *AssetManager) AcquireDefaultFont(size int) *ttf.Font {
+	font, err := am.AcquireFontFromFs(fontData, DEFAULT_FONT_PATH, size)
+	if err != nil {
+		log.Fatalf("Failed to open default font: %s", err)
+	}
+
+	return font
+}
+
+// AcquireChunk returns the audio chunk at path, loading it on the first use
+func (am *AssetManager) AcquireChunk(path string) (*mix.Chunk, error) {
+	return am.AcquireChunkFromFs(osFS, path)
+}
+
+// AcquireChunkFromFs returns the audio chunk at path in fsys, loading it on the first use
+func (am *AssetManager) AcquireChunkFromFs(fsys fs.FS, path string) (*mix.Chunk, error) {
+	return am.acquireChunk(newAssetKey(chunkAsset, fsys, path, 0), func() (*mix.Chunk, error) {
+		return loadChunkFromFs(fsys, path)
+	})
+}
+
+func (am *AssetManager) acquireChunk(key assetKey, load func() (*mix.Chunk, error)) (*mix.Chunk, error) {
+	entry, err := am.acquire(key, func(entry *assetEntry) error {
+		chunk, err := load()
+		entry.asset = chunk
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.asset.(*mix.Chunk), nil
+}
+
+// loadChunkFromFs decodes an audio file of fsys. The chunk keeps the decoded audio, so the file data is not kept
+func loadChunkFromFs(fsys fs.FS, path string) (*mix.Chunk, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	rwops, err := sdl.RWFromMem(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return mix.LoadWAVRW(rwops, true)
+}
+
+// isLoaded reports whether the asset with key is loaded
+func (am *AssetManager) isLoaded(key assetKey) bool {
+	_, exists := am.entries[key]
+	return exists
+}
+
+// RetainTexture adds a reference to a texture already loaded, for a new user sharing it.
+// Each call needs a ReleaseTexture
+func (am *AssetManager) RetainTexture(texture *sdl.Texture) {
+	entry, exists := am.byAsset[texture]
+	if !exists {
+		log.Printf("Retained a texture not loaded by the asset manager\n")
+		return
+	}
+
+	entry.references++
+	am.stats.Hits++
+}
+
+func (am *AssetManager) ReleaseTexture(texture *sdl.Texture) {
+	am.release(texture)
+}
+
+func (am *AssetManager) ReleaseFont(font *ttf.Font) {
+	am.release(font)
+}
+
+func (am *AssetManager) ReleaseChunk(chunk *mix.Chunk) {
+	am.release(chunk)
+}
+
+func (am *AssetManager) release(asset any) {
+	entry, exists := am.byAsset[asset]
+	if !exists {
+		log.Printf("Released an asset (%T) not loaded by the asset manager\n", asset)
+		return
+	}
+
+	entry.references--
+	if entry.references <= 0 {
+		am.free(entry)
+	}
+}
+
+func (am *AssetManager) free(entry *assetEntry) {
+	switch asset := entry.asset.(type) {
+	case *sdl.Texture:
+		asset.Destroy()
+		am.stats.TextureBytes -= entry.bytes
+	case *ttf.Font:
+		asset.Close()
+	case *mix.Chunk:
+		asset.Free()
+	}
+
+	if entry.rwops != nil {
+		entry.rwops.Close()
+	}
+
+	delete(am.entries, entry.key)
+	delete(am.byAsset, entry.asset)
+	am.stats.Frees++
+}
+
+// GetReferences returns how many users the asset has (0 if it is not loaded)
+func (am *AssetManager) GetReferences(asset any) int {
+	if entry, exists := am.byAsset[asset]; exists {
+		return entry.references
+	}
+	return 0
+}
+
+func (am *AssetManager) GetStats() AssetStats {
+	stats := am.stats
+	for _, entry := range am.entries {
+		stats.References += entry.references
+		switch entry.key.kind {
+		case textureAsset:
+			stats.Textures++
+		case fontAsset:
+			stats.Fonts++
+		case chunkAsset:
+			stats.Chunks++
+		}
+	}
+	return stats
+}
+
+// Destroy frees all the assets, even the ones not released (which are reported)
+func (am *AssetManager) Destroy() {
+	for _, entry := range am.entries {
+		log.Printf("Asset %s was not released (%d references)\n", entry.key.path, entry.references)
+		am.free(entry)
+	}
+}
@@ -1,6 +1,7 @@
 package woutils
 
 import (
+	"io/fs"
 	"log"
 
 	"github.com/veandco/go-sdl2/mix"
@@ -8,49 +9,43 @@ import (
 
 type Audio struct {
 	path             string
-	file             *mix.Chunk
+	file             *mix.Chunk // Shared by the asset manager
+	assets           *AssetManager
 	isPlaying        bool
 	playingOnChannel int
 	preferredChannel int
 }
 
 // Use this for long songs that will not be paused or stopped frequently
-func NewAudio(path string) Audio {
-	var err error
-	var audio *mix.Chunk
+func NewAudio(context *GameContext, path string) Audio {
+	return NewAudioFromFs(context, osFS, path)
+}
 
-	if audio, err = mix.LoadWAV(path); err != nil {
+// NewAudioFromFs creates an audio from a file of fsys
+func NewAudioFromFs(context *GameContext, fsys fs.FS, path string) Audio {
+	audio, err := context.GetAssets().AcquireChunkFromFs(fsys, path)
+	if err != nil {
 		log.Fatalln(err)
 	}
 
 	return Audio{
 		path:             path,
 		file:             audio,
+		assets:           context.GetAssets(),
 		isPlaying:        false,
 		playingOnChannel: -1, // -1 means it's not playing
 		preferredChannel: -1, // -1 means it can play in any free channel
 	}
 }
 
-func NewUIAudio(path string) Audio {
-	var err error
-	var audio *mix.Chunk
-
-	if audio, err = mix.LoadWAV(path); err != nil {
-		log.Fatalln(err)
-	}
-
-	return Audio{
-		path:             path,
-		file:             audio,
-		isPlaying:        false,
-		playingOnChannel: -1, // -1 means it's not playing
-		preferredChannel: UI_AUDIO_CHANNEL,
-	}
+func NewUIAudio(context *GameContext, path string) Audio {
+	audio := NewAudio(context, path)
+	audio.preferredChannel = UI_AUDIO_CHANNEL
+	return audio
 }
 
-func NewPlayerAudio(path string) Audio {
-	audio := NewAudio(path)
+func NewPlayerAudio(context *GameContext, path string) Audio {
+	audio := NewAudio(context, path)
 	audio.preferredChannel = PLAYER_AUDIO_CHANNEL
 	return audio
 }
@@ -87,5 +82,8 @@ func (a *Audio) Stop() {
 
 func (a *Audio) Destroy() {
 	a.Stop()
-	a.file.Free()
+	if a.file != nil {
+		a.assets.ReleaseChunk(a.file)
+		a.file = nil
+	}
 }
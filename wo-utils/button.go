@@ -2,7 +2,6 @@ package woutils
 
 import (
 	"embed"
-	"log"
 
 	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
 	"github.com/veandco/go-sdl2/sdl"
@@ -33,10 +32,10 @@ type Button struct {
 	womixins.HideMixin
 	text               *Text
 	destRect           womixins.RectMixin
-	idleTexture        *sdl.Texture
-	pressedTexture     *sdl.Texture
-	hoverTexture       *sdl.Texture
-	disabledTexture    *sdl.Texture
+	idleSkin           NineSlice
+	pressedSkin        NineSlice // Also used when Active
+	hoverSkin          NineSlice
+	disabledSkin       NineSlice
 	collisionRect      womixins.RectMixin
 	collisionThreshold womixins.RectMixin
 	state              ButtonState
@@ -55,10 +54,10 @@ func NewButton() Button {
 		destRect:           destRect,
 		collisionRect:      destRect,
 		collisionThreshold: collisionThreshold,
-		idleTexture:        nil,
-		pressedTexture:     nil,
-		hoverTexture:       nil,
-		disabledTexture:    nil,
+		idleSkin:           NineSlice{},
+		pressedSkin:        NineSlice{},
+		hoverSkin:          NineSlice{},
+		disabledSkin:       NineSlice{},
 		state:              Idle,
 		size:               MediumButton,
 		canListenEvents:    true,
@@ -71,12 +70,10 @@ func NewButtonWithText(context *GameContext, text string) Button {
 	uiText := NewText(context, text)
 	button.text = &uiText
 
-	renderer := context.GetRenderer()
-
-	button.setDefaultIdle(renderer)
-	button.setDefaultHover(renderer)
-	button.setDefaultPressed(renderer)
-	button.setDefaultDisabled(renderer)
+	button.idleSkin = newDefaultButtonSkin(context, "assets/images/buttons/idle.png")
+	button.hoverSkin = newDefaultButtonSkin(context, "assets/images/buttons/hover.png")
+	button.pressedSkin = newDefaultButtonSkin(context, "assets/images/buttons/pressed.png")
+	button.disabledSkin = newDefaultButtonSkin(context, "assets/images/buttons/disabled.png")
 
 	button.updateDimensions()
 	button.setDefaultCollisionThreshold()
@@ -112,32 +109,39 @@ func (b *Button) updateDimensions() {
 	if b.text != nil {
 		width, height := b.text.GetSize()
 		b.destRect.SetSize(width+40, height+40)
-		b.text.SetPosition(b.destRect.X+15, b.destRect.Y+15)
 	}
+	b.placeText()
 	b.calcCollisionRect()
 }
 
-func (b *Button) Render(context *GameContext) {
-	if !b.destRect.HasArea() {
-		return
+// placeText centers the text on the skin, a bit up and left, off its shadow
+func (b *Button) placeText() {
+	if b.text != nil {
+		x, y := b.destRect.GetCenter()
+		b.text.CenterOn(x-5, y-5)
 	}
+}
 
-	var texture *sdl.Texture = nil
+// SetSize resizes the button. The skins keep their borders crisp, and the text stays centered
+func (b *Button) SetSize(width, height int32) {
+	b.destRect.SetSize(width, height)
+	b.placeText()
+	b.calcCollisionRect()
+}
 
-	switch b.state {
-	case Idle:
-		texture = b.idleTexture
-	case Pressed:
-		texture = b.pressedTexture
-	case Hover:
-		texture = b.hoverTexture
-	case Active:
-		texture = b.pressedTexture
-	case Disabled:
-		texture = b.disabledTexture
+func (b *Button) GetSize() (width, height int32) {
+	return b.destRect.W, b.destRect.H
+}
+
+func (b *Button) Render(context *GameContext) {
+	if !b.destRect.HasArea() {
+		return
 	}
 
-	context.GetRenderer().Copy(texture, nil, b.destRect.SdlRect())
+	skin := b.getSkin(b.state)
+	skin.SetPosition(b.destRect.X, b.destRect.Y)
+	skin.SetSize(b.destRect.W, b.destRect.H)
+	skin.Render(context)
 
 	if b.text != nil {
 		b.text.Render(context)
@@ -212,10 +216,11 @@ func (b *Button) AddListeners(screenContext *GameContext) {
 func (b *Button) SetPosition(x, y int32) {
 	b.destRect.SetPosition(x, y)
 	b.calcCollisionRect()
+	b.placeText()
+}
 
-	if b.text != nil {
-		b.text.SetPosition(x+15, y+15)
-	}
+func (b *Button) GetPosition() (x, y int32) {
+	return b.destRect.X, b.destRect.Y
 }
 
 func (b *Button) GetCenter() (x, y int32) {
@@ -231,54 +236,61 @@ func (b *Button) SetText(context *GameContext, text string) {
 	b.updateDimensions()
 }
 
-func getTextureFromEmbedFs(renderer *sdl.Renderer, path string) *sdl.Texture {
-	data, err := buttonImages.ReadFile(path)
-	if err != nil {
-		log.Fatalf("Failed to load default button image (%s): %s", path, err)
-	}
-
-	return LoadTextureFromEmbedFs(renderer, data)
-}
-
-func (b *Button) setDefaultIdle(renderer *sdl.Renderer) {
-	b.idleTexture = getTextureFromEmbedFs(renderer, "assets/images/buttons/idle.png")
+// newDefaultButtonSkin returns a skin of a default button image, shared by all the buttons
+func newDefaultButtonSkin(context *GameContext, path string) NineSlice {
+	return NewNineSliceFromFs(context, buttonImages, path,
+		DEFAULT_BUTTON_INSET_LEFT, DEFAULT_BUTTON_INSET_TOP, DEFAULT_BUTTON_INSET_RIGHT, DEFAULT_BUTTON_INSET_BOTTOM)
 }
 
-func (b *Button) setDefaultPressed(renderer *sdl.Renderer) {
-	b.pressedTexture = getTextureFromEmbedFs(renderer, "assets/images/buttons/pressed.png")
+func (b *Button) getSkin(state ButtonState) *NineSlice {
+	switch state {
+	case Pressed, Active:
+		return &b.pressedSkin
+	case Hover:
+		return &b.hoverSkin
+	case Disabled:
+		return &b.disabledSkin
+	default:
+		return &b.idleSkin
+	}
 }
 
-func (b *Button) setDefaultHover(renderer *sdl.Renderer) {
-	b.hoverTexture = getTextureFromEmbedFs(renderer, "assets/images/buttons/hover.png")
+// SetSkin sets the image of the button in a state (Active uses the Pressed skin). The button keeps its
+// own copy of the skin, so the same skin can be used for several states and buttons, and must still be destroyed
+func (b *Button) SetSkin(state ButtonState, skin NineSlice) {
+	current := b.getSkin(state)
+	current.Destroy()
+	*current = skin.Clone()
 }
 
-func (b *Button) setDefaultDisabled(renderer *sdl.Renderer) {
-	b.disabledTexture = getTextureFromEmbedFs(renderer, "assets/images/buttons/disabled.png")
+// setSkinFromFile replaces the skin of a state with the image at path, stretched without insets
+func (b *Button) setSkinFromFile(context *GameContext, state ButtonState, path string) {
+	skin := NewNineSlice(context, path, 0, 0, 0, 0)
+	b.SetSkin(state, skin)
+	skin.Destroy()
 }
 
-func getTextureFromFile(renderer *sdl.Renderer, path string) *sdl.Texture {
-	texture, err := LoadTexture(renderer, path)
-	if err != nil {
-		log.Fatalf("Failed to load button image \"%s\" and convert to texture: %s", path, err)
+// SetInsets sets the insets of the skins of all the states (e.g. after SetIdle with a custom image)
+func (b *Button) SetInsets(left, top, right, bottom int32) {
+	for _, state := range []ButtonState{Idle, Pressed, Hover, Disabled} {
+		b.getSkin(state).SetInsets(left, top, right, bottom)
 	}
-
-	return texture
 }
 
 func (b *Button) SetIdle(context *GameContext, path string) {
-	b.idleTexture = getTextureFromFile(context.GetRenderer(), path)
+	b.setSkinFromFile(context, Idle, path)
 }
 
 func (b *Button) SetPressed(context *GameContext, path string) {
-	b.pressedTexture = getTextureFromFile(context.GetRenderer(), path)
+	b.setSkinFromFile(context, Pressed, path)
 }
 
 func (b *Button) SetHover(context *GameContext, path string) {
-	b.hoverTexture = getTextureFromFile(context.GetRenderer(), path)
+	b.setSkinFromFile(context, Hover, path)
 }
 
 func (b *Button) SetDisabled(context *GameContext, path string) {
-	b.disabledTexture = getTextureFromFile(context.GetRenderer(), path)
+	b.setSkinFromFile(context, Disabled, path)
 }
 
 func (b *Button) Disable() {
@@ -290,21 +302,10 @@ func (b *Button) Enable() {
 }
 
 func (b *Button) Destroy() {
-	if b.idleTexture != nil {
-		b.idleTexture.Destroy()
-	}
-
-	if b.pressedTexture != nil {
-		b.pressedTexture.Destroy()
-	}
-
-	if b.hoverTexture != nil {
-		b.hoverTexture.Destroy()
-	}
-
-	if b.disabledTexture != nil {
-		b.disabledTexture.Destroy()
-	}
+	b.idleSkin.Destroy()
+	b.pressedSkin.Destroy()
+	b.hoverSkin.Destroy()
+	b.disabledSkin.Destroy()
 
 	if b.text != nil {
 		b.text.Destroy()
@@ -6,4 +6,21 @@ const (
 
 	UI_AUDIO_CHANNEL     int = 0
 	PLAYER_AUDIO_CHANNEL int = 1
+
+	DEFAULT_FRAME_DURATION uint32 = 100 // Milliseconds, for sprite sheets without frame durations
+
+	DEFAULT_FONT_PATH string = "assets/fonts/default.ttf" // Embedded in the engine
+	DEFAULT_FONT_SIZE int    = 16
+
+	// Borders of the default button images, kept crisp when the buttons are resized
+	DEFAULT_BUTTON_INSET_LEFT   int32 = 24
+	DEFAULT_BUTTON_INSET_TOP    int32 = 20
+	DEFAULT_BUTTON_INSET_RIGHT  int32 = 28
+	DEFAULT_BUTTON_INSET_BOTTOM int32 = 28
+
+	DEFAULT_MAX_PARTICLES int     = 100
+	DEFAULT_PARTICLE_SIZE float32 = 4 // Pixels, for particles without texture
+	// Longest step of the automatic updates, so an emitter that wasn't rendered for a while
+	// (e.g. hidden) doesn't emit all the particles of that time at once
+	MAX_PARTICLE_STEP_MS uint64 = 100
 )
@@ -0,0 +1,126 @@
+package woutils
+
+import (
+	"log"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type Facing uint8
+
+const (
+	FacingN Facing = iota
+	FacingNE
+	FacingE
+	FacingSE
+	FacingS
+	FacingSW
+	FacingW
+	FacingNW
+)
+
+var facingNames = [8]string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+func (f Facing) String() string {
+	return facingNames[f%8]
+}
+
+// Mirrored returns the facing mirrored horizontally (E is W, NE is NW, N is N)
+func (f Facing) Mirrored() Facing {
+	return (8 - f%8) % 8
+}
+
+// FacingFromVector returns the facing nearest to a movement in map coordinates (y grows down, so N is up).
+// isometricRatio is the tile width divided by the tile height (2 in most isometric maps). It undoes the
+// flattening of the isometric projection, so moving along a row or column of tiles faces a diagonal.
+// Map coordinates don't depend on the camera zoom, so neither does the facing
+func FacingFromVector(dx, dy float64, isometricRatio float64) Facing {
+	angle := math.Atan2(dy*isometricRatio, dx) // 0 is E, growing clockwise
+	sector := int(math.Round(angle / (math.Pi / 4)))
+	return Facing((sector + 2 + 8) % 8)
+}
+
+// DirectionalSprite is an AnimatedSprite with a clip for each facing of each animation, named
+// animation + "_" + facing (e.g. "walk_SE"). When a facing has no clip, the clip of the mirrored
+// facing is drawn mirrored, so only the facings of one side need to be drawn (e.g. "walk_E" for "walk_W").
+type DirectionalSprite struct {
+	AnimatedSprite
+	animation      string
+	facing         Facing
+	isometricRatio float64
+}
+
+func NewDirectionalSprite(spriteSheet *SpriteSheet) DirectionalSprite {
+	return DirectionalSprite{
+		AnimatedSprite: NewAnimatedSprite(spriteSheet),
+		animation:      "",
+		facing:         FacingS,
+		isometricRatio: 2,
+	}
+}
+
+// SetIsometricRatio sets the tile width divided by the tile height of the map (see FacingFromVector)
+func (ds *DirectionalSprite) SetIsometricRatio(isometricRatio float64) {
+	ds.isometricRatio = isometricRatio
+}
+
+// PlayAnimation plays the animation (e.g. "walk") in the current facing. Playing the animation
+// already playing does nothing
+func (ds *DirectionalSprite) PlayAnimation(animation string) {
+	if animation == ds.animation && ds.IsPlaying() {
+		return
+	}
+
+	ds.animation = animation
+	clip, flip := ds.getClip()
+	ds.SetFlip(flip)
+	ds.Play(clip.Name)
+}
+
+// SetFacing turns the sprite, keeping the progress of the animation
+func (ds *DirectionalSprite) SetFacing(facing Facing) {
+	if facing == ds.facing {
+		return
+	}
+
+	ds.facing = facing
+	if ds.animation == "" {
+		return
+	}
+
+	clip, flip := ds.getClip()
+	ds.SetFlip(flip)
+	ds.changeClip(clip)
+}
+
+// FaceMovement turns the sprite to the direction of a movement in map coordinates.
+// Without movement (0, 0) the facing is kept
+func (ds *DirectionalSprite) FaceMovement(dx, dy float64) {
+	if dx == 0 && dy == 0 {
+		return
+	}
+	ds.SetFacing(FacingFromVector(dx, dy, ds.isometricRatio))
+}
+
+func (ds *DirectionalSprite) GetFacing() Facing {
+	return ds.facing
+}
+
+func (ds *DirectionalSprite) GetAnimation() string {
+	return ds.animation
+}
+
+// getClip returns the clip of the animation in the current facing, and how to flip it
+func (ds *DirectionalSprite) getClip() (*AnimationClip, sdl.RendererFlip) {
+	if clip := ds.spriteSheet.GetClip(ds.animation + "_" + ds.facing.String()); clip != nil {
+		return clip, sdl.FLIP_NONE
+	}
+
+	if clip := ds.spriteSheet.GetClip(ds.animation + "_" + ds.facing.Mirrored().String()); clip != nil {
+		return clip, sdl.FLIP_HORIZONTAL
+	}
+
+	log.Fatalf("Sprite sheet has no clip %s_%s (or mirrored %s_%s)", ds.animation, ds.facing, ds.animation, ds.facing.Mirrored())
+	return nil, sdl.FLIP_NONE
+}
@@ -0,0 +1,279 @@
+package woutils
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/gfx"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type DrawSpace uint8
+
+const (
+	ScreenSpace DrawSpace = iota // Coordinates and sizes are pixels of the window
+	WorldSpace                   // Coordinates and sizes are of the world (map), moved and zoomed by the camera
+)
+
+// Drawer draws lines and shapes (e.g. selection outlines, range indicators, collision shapes and tile highlights).
+// In WorldSpace the camera translation and zoom are applied. It can be used inside and outside of the
+// rendering of the map, where the renderer is already zoomed (see GameContext.InitRenderZoom)
+type Drawer struct {
+	context *GameContext
+	space   DrawSpace
+	color   sdl.Color
+}
+
+func NewDrawer(context *GameContext, space DrawSpace) Drawer {
+	return Drawer{
+		context: context,
+		space:   space,
+		color:   sdl.Color{R: 255, G: 255, B: 255, A: 255},
+	}
+}
+
+func (d *Drawer) SetSpace(space DrawSpace) {
+	d.space = space
+}
+
+func (d *Drawer) GetSpace() DrawSpace {
+	return d.space
+}
+
+func (d *Drawer) SetColor(r, g, b, a uint8) {
+	d.color = sdl.Color{R: r, G: g, B: b, A: a}
+}
+
+func (d *Drawer) GetColor() sdl.Color {
+	return d.color
+}
+
+func (d *Drawer) toRenderer(x, y float32) (float32, float32) {
+	return spaceToRenderer(d.context, d.space, x, y)
+}
+
+func (d *Drawer) toRendererLength(length float32) float32 {
+	return spaceLengthToRenderer(d.context, d.space, length)
+}
+
+// spaceTransform returns how points of the space are converted to the coordinates of the renderer,
+// undoing its current scale: (x + offsetX) * factorX, (y + offsetY) * factorY
+func spaceTransform(context *GameContext, space DrawSpace) (offsetX, offsetY, factorX, factorY float32) {
+	scaleX, scaleY := context.GetRenderer().GetScale()
+	if space == WorldSpace {
+		camera := &context.Camera
+		return float32(camera.translationX), float32(camera.translationY), camera.zoom / scaleX, camera.zoom / scaleY
+	}
+	return 0, 0, 1 / scaleX, 1 / scaleY
+}
+
+// spaceToRenderer converts a point of the space to the coordinates of the renderer (see spaceTransform)
+func spaceToRenderer(context *GameContext, space DrawSpace, x, y float32) (float32, float32) {
+	offsetX, offsetY, factorX, factorY := spaceTransform(context, space)
+	return (x + offsetX) * factorX, (y + offsetY) * factorY
+}
+
+// spaceLengthToRenderer converts a length (e.g. a thickness or a radius) to the renderer, along the x axis
+func spaceLengthToRenderer(context *GameContext, space DrawSpace, length float32) float32 {
+	scaleX, _ := context.GetRenderer().GetScale()
+	if space == WorldSpace {
+		length *= context.Camera.zoom
+	}
+	return length / scaleX
+}
+
+// begin sets the color of the renderer, returning a function that restores the previous one
+func (d *Drawer) begin() (restore func()) {
+	renderer := d.context.GetRenderer()
+	red, green, blue, alpha, _ := renderer.GetDrawColor()
+	var blendMode sdl.BlendMode
+	renderer.GetDrawBlendMode(&blendMode)
+
+	renderer.SetDrawColor(d.color.R, d.color.G, d.color.B, d.color.A)
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+
+	return func() {
+		renderer.SetDrawColor(red, green, blue, alpha)
+		renderer.SetDrawBlendMode(blendMode)
+	}
+}
+
+func (d *Drawer) Line(x1, y1, x2, y2 float32) {
+	defer d.begin()()
+
+	x1, y1 = d.toRenderer(x1, y1)
+	x2, y2 = d.toRenderer(x2, y2)
+	d.context.GetRenderer().DrawLineF(x1, y1, x2, y2)
+}
+
+// ThickLine draws a line with a thickness. In WorldSpace the thickness is zoomed too
+func (d *Drawer) ThickLine(x1, y1, x2, y2, thickness float32) {
+	x1, y1 = d.toRenderer(x1, y1)
+	x2, y2 = d.toRenderer(x2, y2)
+	d.fillThickLine(x1, y1, x2, y2, d.toRendererLength(thickness))
+}
+
+// fillThickLine fills the rectangle around the line, in coordinates of the renderer
+func (d *Drawer) fillThickLine(x1, y1, x2, y2, thickness float32) {
+	length := float32(math.Hypot(float64(x2-x1), float64(y2-y1)))
+	if length == 0 {
+		return
+	}
+
+	// Half the thickness, perpendicular to the line
+	offsetX := -(y2 - y1) / length * thickness / 2
+	offsetY := (x2 - x1) / length * thickness / 2
+
+	d.fillGeometry([]sdl.FPoint{
+		{X: x1 + offsetX, Y: y1 + offsetY},
+		{X: x2 + offsetX, Y: y2 + offsetY},
+		{X: x2 - offsetX, Y: y2 - offsetY},
+		{X: x1 - offsetX, Y: y1 - offsetY},
+	})
+}
+
+// Lines draws lines joining the points. Closes the shape if closed is true
+func (d *Drawer) Lines(points []sdl.FPoint, closed bool) {
+	if len(points) < 2 {
+		return
+	}
+	defer d.begin()()
+
+	rendererPoints := make([]sdl.FPoint, 0, len(points)+1)
+	for _, point := range points {
+		x, y := d.toRenderer(point.X, point.Y)
+		rendererPoints = append(rendererPoints, sdl.FPoint{X: x, Y: y})
+	}
+	if closed {
+		rendererPoints = append(rendererPoints, rendererPoints[0])
+	}
+	d.context.GetRenderer().DrawLinesF(rendererPoints)
+}
+
+func (d *Drawer) Rect(x, y, w, h float32) {
+	d.Lines(rectPoints(x, y, w, h), true)
+}
+
+func (d *Drawer) FillRect(x, y, w, h float32) {
+	d.FillConvexPolygon(rectPoints(x, y, w, h))
+}
+
+// Diamond draws the isometric diamond inscribed in the rectangle (e.g. the outline of a tile)
+func (d *Drawer) Diamond(x, y, w, h float32) {
+	d.Lines(diamondPoints(x, y, w, h), true)
+}
+
+func (d *Drawer) FillDiamond(x, y, w, h float32) {
+	d.FillConvexPolygon(diamondPoints(x, y, w, h))
+}
+
+// TileDiamond draws the outline of a tile of the map, raised to its elevation. Use it in WorldSpace
+func (d *Drawer) TileDiamond(gameMap *GameMap, col, row int32) {
+	d.Diamond(tileDiamondRect(gameMap, col, row))
+}
+
+// FillTileDiamond fills a tile of the map, raised to its elevation. Use it in WorldSpace
+func (d *Drawer) FillTileDiamond(gameMap *GameMap, col, row int32) {
+	d.FillDiamond(tileDiamondRect(gameMap, col, row))
+}
+
+func (d *Drawer) Circle(x, y, radius float32) {
+	d.Ellipse(x, y, radius, radius)
+}
+
+func (d *Drawer) FillCircle(x, y, radius float32) {
+	d.FillEllipse(x, y, radius, radius)
+}
+
+// Ellipse draws an ellipse centered on the point. Ellipses with half the height look round
+// on isometric maps (e.g. the range of a unit)
+func (d *Drawer) Ellipse(x, y, radiusX, radiusY float32) {
+	d.Lines(d.ellipsePoints(x, y, radiusX, radiusY), true)
+}
+
+func (d *Drawer) FillEllipse(x, y, radiusX, radiusY float32) {
+	d.FillConvexPolygon(d.ellipsePoints(x, y, radiusX, radiusY))
+}
+
+// Polygon draws the outline of a polygon
+func (d *Drawer) Polygon(points []sdl.FPoint) {
+	d.Lines(points, true)
+}
+
+// FillPolygon fills any simple polygon, convex or not. FillConvexPolygon is faster for convex polygons
+func (d *Drawer) FillPolygon(points []sdl.FPoint) {
+	if len(points) < 3 {
+		return
+	}
+
+	xs := make([]int16, len(points))
+	ys := make([]int16, len(points))
+	for i, point := range points {
+		x, y := d.toRenderer(point.X, point.Y)
+		xs[i], ys[i] = int16(math.Round(float64(x))), int16(math.Round(float64(y)))
+	}
+
+	renderer := d.context.GetRenderer()
+	red, green, blue, alpha, _ := renderer.GetDrawColor()
+	defer renderer.SetDrawColor(red, green, blue, alpha)
+	gfx.FilledPolygonColor(renderer, xs, ys, d.color)
+}
+
+// FillConvexPolygon fills a convex polygon (e.g. rectangles, diamonds and ellipses)
+func (d *Drawer) FillConvexPolygon(points []sdl.FPoint) {
+	if len(points) < 3 {
+		return
+	}
+
+	rendererPoints := make([]sdl.FPoint, len(points))
+	for i, point := range points {
+		rendererPoints[i].X, rendererPoints[i].Y = d.toRenderer(point.X, point.Y)
+	}
+	d.fillGeometry(rendererPoints)
+}
+
+// fillGeometry fills a convex polygon in coordinates of the renderer, as a fan of triangles
+func (d *Drawer) fillGeometry(points []sdl.FPoint) {
+	defer d.begin()()
+
+	vertices := make([]sdl.Vertex, len(points))
+	for i, point := range points {
+		vertices[i] = sdl.Vertex{Position: point, Color: d.color}
+	}
+
+	indices := make([]int32, 0, (len(points)-2)*3)
+	for i := int32(1); i < int32(len(points))-1; i++ {
+		indices = append(indices, 0, i, i+1)
+	}
+
+	d.context.GetRenderer().RenderGeometry(nil, vertices, indices)
+}
+
+// ellipsePoints returns points around the ellipse, more for bigger ellipses on the screen
+func (d *Drawer) ellipsePoints(x, y, radiusX, radiusY float32) []sdl.FPoint {
+	radius := d.toRendererLength(max(radiusX, radiusY))
+	segments := min(max(int(radius/2), 12), 180)
+
+	points := make([]sdl.FPoint, segments)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / float64(segments)
+		points[i] = sdl.FPoint{
+			X: x + radiusX*float32(math.Cos(angle)),
+			Y: y + radiusY*float32(math.Sin(angle)),
+		}
+	}
+	return points
+}
+
+func rectPoints(x, y, w, h float32) []sdl.FPoint {
+	return []sdl.FPoint{{X: x, Y: y}, {X: x + w, Y: y}, {X: x + w, Y: y + h}, {X: x, Y: y + h}}
+}
+
+func diamondPoints(x, y, w, h float32) []sdl.FPoint {
+	return []sdl.FPoint{{X: x + w/2, Y: y}, {X: x + w, Y: y + h/2}, {X: x + w/2, Y: y + h}, {X: x, Y: y + h/2}}
+}
+
+func tileDiamondRect(gameMap *GameMap, col, row int32) (x, y, w, h float32) {
+	worldX, worldY := gameMap.TileToWorld(col, row)
+	worldY -= gameMap.GetElevationOffset(gameMap.GetElevation(col, row))
+	return float32(worldX), float32(worldY), float32(gameMap.tileWidth), float32(gameMap.tileHeight)
+}
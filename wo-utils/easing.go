@@ -0,0 +1,144 @@
+package woutils
+
+import "math"
+
+// EasingFunc maps the progress of an animation, from 0 to 1, to the progress of its value
+// (usually from 0 to 1 too), so it can start or end slowly
+type EasingFunc func(t float64) float64
+
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+func EaseInQuad(t float64) float64 {
+	return t * t
+}
+
+func EaseOutQuad(t float64) float64 {
+	return 1 - (1-t)*(1-t)
+}
+
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return 1 - 2*(1-t)*(1-t)
+}
+
+func EaseInCubic(t float64) float64 {
+	return t * t * t
+}
+
+func EaseOutCubic(t float64) float64 {
+	return 1 - (1-t)*(1-t)*(1-t)
+}
+
+func EaseInOutCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	return 1 - 4*(1-t)*(1-t)*(1-t)
+}
+
+func EaseInQuart(t float64) float64 {
+	return t * t * t * t
+}
+
+func EaseOutQuart(t float64) float64 {
+	return 1 - math.Pow(1-t, 4)
+}
+
+func EaseInOutQuart(t float64) float64 {
+	if t < 0.5 {
+		return 8 * t * t * t * t
+	}
+	return 1 - 8*math.Pow(1-t, 4)
+}
+
+func EaseInSine(t float64) float64 {
+	return 1 - math.Cos(t*math.Pi/2)
+}
+
+func EaseOutSine(t float64) float64 {
+	return math.Sin(t * math.Pi / 2)
+}
+
+func EaseInOutSine(t float64) float64 {
+	return (1 - math.Cos(t*math.Pi)) / 2
+}
+
+func EaseInExpo(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Pow(2, 10*t-10)
+}
+
+func EaseOutExpo(t float64) float64 {
+	if t >= 1 {
+		return 1
+	}
+	return 1 - math.Pow(2, -10*t)
+}
+
+func EaseInOutExpo(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	if t < 0.5 {
+		return math.Pow(2, 20*t-10) / 2
+	}
+	return (2 - math.Pow(2, -20*t+10)) / 2
+}
+
+// EaseInBack moves a bit backwards before going forward
+func EaseInBack(t float64) float64 {
+	const overshoot = 1.70158
+	return (overshoot+1)*t*t*t - overshoot*t*t
+}
+
+// EaseOutBack goes a bit past the end before settling (e.g. panels sliding in)
+func EaseOutBack(t float64) float64 {
+	return 1 - EaseInBack(1-t)
+}
+
+func EaseInOutBack(t float64) float64 {
+	if t < 0.5 {
+		return EaseInBack(t*2) / 2
+	}
+	return 1 - EaseInBack((1-t)*2)/2
+}
+
+// EaseOutElastic goes past the end and springs back a few times
+func EaseOutElastic(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return math.Pow(2, -10*t)*math.Sin((t*10-0.75)*(2*math.Pi/3)) + 1
+}
+
+func EaseInElastic(t float64) float64 {
+	return 1 - EaseOutElastic(1-t)
+}
+
+// EaseOutBounce bounces at the end, like a falling ball
+func EaseOutBounce(t float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+func EaseInBounce(t float64) float64 {
+	return 1 - EaseOutBounce(1-t)
+}
@@ -0,0 +1,48 @@
+package woutils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEasingEndpoints(t *testing.T) {
+	easings := map[string]EasingFunc{
+		"EaseLinear":     EaseLinear,
+		"EaseInQuad":     EaseInQuad,
+		"EaseOutQuad":    EaseOutQuad,
+		"EaseInOutQuad":  EaseInOutQuad,
+		"EaseInCubic":    EaseInCubic,
+		"EaseOutCubic":   EaseOutCubic,
+		"EaseInOutCubic": EaseInOutCubic,
+		"EaseInQuart":    EaseInQuart,
+		"EaseOutQuart":   EaseOutQuart,
+		"EaseInOutQuart": EaseInOutQuart,
+		"EaseInSine":     EaseInSine,
+		"EaseOutSine":    EaseOutSine,
+		"EaseInOutSine":  EaseInOutSine,
+		"EaseInExpo":     EaseInExpo,
+		"EaseOutExpo":    EaseOutExpo,
+		"EaseInOutExpo":  EaseInOutExpo,
+		"EaseInBack":     EaseInBack,
+		"EaseOutBack":    EaseOutBack,
+		"EaseInOutBack":  EaseInOutBack,
+		"EaseInElastic":  EaseInElastic,
+		"EaseOutElastic": EaseOutElastic,
+		"EaseInBounce":   EaseInBounce,
+		"EaseOutBounce":  EaseOutBounce,
+	}
+
+	for name, easing := range easings {
+		t.Run(name, func(t *testing.T) {
+			if start := easing(0); math.Abs(start) > 1e-9 {
+				t.Errorf("f(0) = %v, expected 0", start)
+			}
+			if end := easing(1); math.Abs(end-1) > 1e-9 {
+				t.Errorf("f(1) = %v, expected 1", end)
+			}
+			if middle := easing(0.5); middle <= -1 || middle >= 2 {
+				t.Errorf("f(0.5) = %v, too far from the range", middle)
+			}
+		})
+	}
+}
@@ -1,20 +1,39 @@
 package woutils
 
 import (
+	"encoding/json"
 	"encoding/xml"
-	"io"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
+// osFileSystem reads the files of the operating system, by absolute paths or relative to the working directory
+// (unlike os.DirFS, paths with ".." are allowed). The loaders without an fs.FS read from it
+type osFileSystem struct{}
+
+var osFS fs.FS = osFileSystem{}
+
+func (osFileSystem) Open(name string) (fs.File, error) {
+	return os.Open(filepath.FromSlash(name))
+}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.FromSlash(name))
+}
+
 func GetFileName(path string) string {
 	return path[strings.LastIndex(path, "/")+1:]
 }
 
+// Deprecated: use ResolvePath to find files relative to another file
 func GetDirFromPath(path string) string {
 	return path[:len(path)-len(GetFileName(path))]
 }
 
+// Deprecated: use ResolvePath to find files relative to another file
 func AppendOnPath(path, toAppend string) string {
 	trimmedPath := strings.TrimSuffix(path, "/")
 	trimmedToAppend := strings.TrimPrefix(toAppend, "/")
@@ -22,24 +41,71 @@ func AppendOnPath(path, toAppend string) string {
 	return trimmedPath + "/" + trimmedToAppend
 }
 
+// ResolvePath returns the path of reference, relative to the file at basePath
+// (e.g. the tilesets of a TMX file or the image of a TSX file)
+func ResolvePath(basePath, reference string) string {
+	if path.IsAbs(reference) {
+		return reference
+	}
+	return path.Join(path.Dir(basePath), reference)
+}
+
 // ReadXml reads an XML file and unmarshals it into the xmlStruct interface
 func ReadXml(filePath string, xmlStruct interface{}) error {
-	xmlFile, err := os.Open(filePath)
+	return ReadXmlFromFs(osFS, filePath, xmlStruct)
+}
 
+// ReadXmlFromFs reads an XML file of fsys and unmarshals it into the xmlStruct interface
+func ReadXmlFromFs(fsys fs.FS, filePath string, xmlStruct interface{}) error {
+	bytes, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
 		return err
 	}
-	defer xmlFile.Close()
 
-	bytes, err := io.ReadAll(xmlFile)
+	err = xml.Unmarshal(bytes, &xmlStruct)
 	if err != nil {
 		return err
 	}
 
-	err = xml.Unmarshal(bytes, &xmlStruct)
+	return nil
+}
+
+// ReadJson reads a JSON file and unmarshals it into the jsonStruct interface
+func ReadJson(filePath string, jsonStruct interface{}) error {
+	return ReadJsonFromFs(osFS, filePath, jsonStruct)
+}
+
+// ReadJsonFromFs reads a JSON file of fsys and unmarshals it into the jsonStruct interface
+func ReadJsonFromFs(fsys fs.FS, filePath string, jsonStruct interface{}) error {
+	bytes, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return json.Unmarshal(bytes, jsonStruct)
+}
+
+// WriteXml marshals the xmlStruct interface into an XML file, replacing its content
+func WriteXml(filePath string, xmlStruct interface{}) error {
+	xmlFile, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer xmlFile.Close()
+
+	if _, err = xmlFile.WriteString(xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(xmlFile)
+	encoder.Indent("", " ")
+	if err = encoder.Encode(xmlStruct); err != nil {
+		return err
+	}
+
+	if _, err = xmlFile.WriteString("\n"); err != nil {
+		return err
+	}
+
+	return xmlFile.Close()
 }
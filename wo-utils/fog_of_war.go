@@ -0,0 +1,320 @@
+package woutils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type TileVisibility uint8
+
+const (
+	Unexplored TileVisibility = iota
+	Explored                  // Seen before, but not visible now
+	Visible
+)
+
+// Viewer is something that sees the map (a unit, a tower...), from a tile, up to Radius tiles away
+type Viewer struct {
+	Col    int32
+	Row    int32
+	Radius int32
+}
+
+type factionVisibility struct {
+	explored []bool
+	visible  []bool
+}
+
+// FogOfWar keeps what each faction sees and has explored of a GameMap.
+// The field of view is computed with shadowcasting, and tiles with the property opaque=true
+// (on the tile or on its layer) block the view. The entities of the map are drawn only on the tiles
+// the viewing faction sees.
+type FogOfWar struct {
+	gameMap         *GameMap
+	opaque          []bool
+	factions        map[string]*factionVisibility
+	viewingFaction  string // Faction whose fog is drawn over the map
+	unexploredColor sdl.Color
+	exploredColor   sdl.Color
+	vertices        []sdl.Vertex // Reused every frame to draw the fog without allocating
+	indices         []int32
+}
+
+// NewFogOfWar creates the fog for the map and draws it over the map (see GameMap.SetFogOfWar)
+func NewFogOfWar(gameMap *GameMap) *FogOfWar {
+	fog := &FogOfWar{
+		gameMap:         gameMap,
+		opaque:          make([]bool, gameMap.mapWidth*gameMap.mapHeight),
+		factions:        make(map[string]*factionVisibility),
+		viewingFaction:  "",
+		unexploredColor: sdl.Color{R: 0, G: 0, B: 0, A: 255},
+		exploredColor:   sdl.Color{R: 0, G: 0, B: 0, A: 150},
+	}
+
+	for row := int32(0); row < gameMap.mapHeight; row++ {
+		for col := int32(0); col < gameMap.mapWidth; col++ {
+			fog.updateOpacity(col, row)
+		}
+	}
+
+	gameMap.AddTileChangeListener(func(layer int, col, row int32, oldGid, newGid uint32) {
+		fog.updateOpacity(col, row)
+	})
+	gameMap.SetFogOfWar(fog)
+
+	return fog
+}
+
+func (fw *FogOfWar) updateOpacity(col, row int32) {
+	opaque := false
+	for layer := range fw.gameMap.layers {
+		gid := fw.gameMap.GetTile(layer, col, row)
+		if gid == 0 {
+			continue
+		}
+
+		layerOpaque := fw.gameMap.layers[layer].properties.GetBool("opaque", false)
+		if fw.gameMap.GetTileProperties(gid).GetBool("opaque", layerOpaque) {
+			opaque = true
+			break
+		}
+	}
+
+	fw.opaque[row*fw.gameMap.mapWidth+col] = opaque
+}
+
+// IsOpaque reports whether the tile blocks the view. Tiles outside the map are opaque
+func (fw *FogOfWar) IsOpaque(col, row int32) bool {
+	return !fw.gameMap.IsInside(col, row) || fw.opaque[row*fw.gameMap.mapWidth+col]
+}
+
+func (fw *FogOfWar) getFaction(faction string) *factionVisibility {
+	visibility, ok := fw.factions[faction]
+	if !ok {
+		tileCount := fw.gameMap.mapWidth * fw.gameMap.mapHeight
+		visibility = &factionVisibility{
+			explored: make([]bool, tileCount),
+			visible:  make([]bool, tileCount),
+		}
+		fw.factions[faction] = visibility
+	}
+	return visibility
+}
+
+// UpdateVisibility recomputes what the faction sees from its viewers.
+// The tiles seen are also marked as explored.
+func (fw *FogOfWar) UpdateVisibility(faction string, viewers []Viewer) {
+	visibility := fw.getFaction(faction)
+	clear(visibility.visible)
+
+	reveal := func(col, row int32) {
+		if fw.gameMap.IsInside(col, row) {
+			index := row*fw.gameMap.mapWidth + col
+			visibility.visible[index] = true
+			visibility.explored[index] = true
+		}
+	}
+
+	for _, viewer := range viewers {
+		reveal(viewer.Col, viewer.Row)
+		for _, octant := range shadowcastOctants {
+			fw.castLight(viewer, 1, 1.0, 0.0, octant, reveal)
+		}
+	}
+}
+
+// Multipliers that transform the first octant into each of the 8 octants around the viewer
+var shadowcastOctants = [8][4]int32{
+	{1, 0, 0, 1}, {0, 1, 1, 0}, {0, -1, 1, 0}, {-1, 0, 0, 1},
+	{-1, 0, 0, -1}, {0, -1, -1, 0}, {0, 1, -1, 0}, {1, 0, 0, -1},
+}
+
+// castLight scans one octant row by row (recursive shadowcasting). Each opaque tile starts a
+// shadow, and the rows after it are scanned only between the slopes that are still lit
+func (fw *FogOfWar) castLight(viewer Viewer, startRow int32, startSlope, endSlope float64, octant [4]int32, reveal func(col, row int32)) {
+	if startSlope < endSlope {
+		return
+	}
+
+	radiusSquared := viewer.Radius * viewer.Radius
+	newStartSlope := 0.0
+
+	for distance := startRow; distance <= viewer.Radius; distance++ {
+		blocked := false
+		deltaY := -distance
+
+		for deltaX := -distance; deltaX <= 0; deltaX++ {
+			col := viewer.Col + deltaX*octant[0] + deltaY*octant[1]
+			row := viewer.Row + deltaX*octant[2] + deltaY*octant[3]
+			leftSlope := (float64(deltaX) - 0.5) / (float64(deltaY) + 0.5)
+			rightSlope := (float64(deltaX) + 0.5) / (float64(deltaY) - 0.5)
+
+			if startSlope < rightSlope {
+				continue
+			} else if endSlope > leftSlope {
+				break
+			}
+
+			if deltaX*deltaX+deltaY*deltaY <= radiusSquared {
+				reveal(col, row)
+			}
+
+			if blocked {
+				if fw.IsOpaque(col, row) {
+					newStartSlope = rightSlope
+					continue
+				}
+				blocked = false
+				startSlope = newStartSlope
+			} else if fw.IsOpaque(col, row) && distance < viewer.Radius {
+				blocked = true
+				fw.castLight(viewer, distance+1, startSlope, leftSlope, octant, reveal)
+				newStartSlope = rightSlope
+			}
+		}
+
+		if blocked {
+			break
+		}
+	}
+}
+
+func (fw *FogOfWar) GetVisibility(faction string, col, row int32) TileVisibility {
+	visibility, ok := fw.factions[faction]
+	if !ok || !fw.gameMap.IsInside(col, row) {
+		return Unexplored
+	}
+
+	index := row*fw.gameMap.mapWidth + col
+	if visibility.visible[index] {
+		return Visible
+	}
+	if visibility.explored[index] {
+		return Explored
+	}
+	return Unexplored
+}
+
+func (fw *FogOfWar) IsVisible(faction string, col, row int32) bool {
+	return fw.GetVisibility(faction, col, row) == Visible
+}
+
+// SetViewingFaction chooses the faction whose fog is drawn over the map (usually the player)
+func (fw *FogOfWar) SetViewingFaction(faction string) {
+	fw.viewingFaction = faction
+}
+
+// SetColors changes the colors drawn over the unexplored and explored (but not visible) tiles
+func (fw *FogOfWar) SetColors(unexplored, explored sdl.Color) {
+	fw.unexploredColor = unexplored
+	fw.exploredColor = explored
+}
+
+type exploredState struct {
+	Width    int32             `json:"width"`
+	Height   int32             `json:"height"`
+	Factions map[string][]byte `json:"factions"` // Bit set of the explored tiles
+}
+
+// MarshalExplored serializes the explored tiles of every faction (e.g. to a save file)
+func (fw *FogOfWar) MarshalExplored() ([]byte, error) {
+	state := exploredState{
+		Width:    fw.gameMap.mapWidth,
+		Height:   fw.gameMap.mapHeight,
+		Factions: make(map[string][]byte, len(fw.factions)),
+	}
+
+	for faction, visibility := range fw.factions {
+		bits := make([]byte, (len(visibility.explored)+7)/8)
+		for index, explored := range visibility.explored {
+			if explored {
+				bits[index/8] |= 1 << (index % 8)
+			}
+		}
+		state.Factions[faction] = bits
+	}
+
+	return json.Marshal(state)
+}
+
+// UnmarshalExplored restores the explored tiles saved with MarshalExplored.
+// Nothing is visible until the next UpdateVisibility
+func (fw *FogOfWar) UnmarshalExplored(data []byte) error {
+	var state exploredState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	if state.Width != fw.gameMap.mapWidth || state.Height != fw.gameMap.mapHeight {
+		return fmt.Errorf("explored state is for a %dx%d map, but the map is %dx%d", state.Width, state.Height, fw.gameMap.mapWidth, fw.gameMap.mapHeight)
+	}
+
+	for faction, bits := range state.Factions {
+		visibility := fw.getFaction(faction)
+		if len(bits) != (len(visibility.explored)+7)/8 {
+			return fmt.Errorf("invalid explored state size for faction %s: %d", faction, len(bits))
+		}
+
+		clear(visibility.visible)
+		for index := range visibility.explored {
+			visibility.explored[index] = bits[index/8]&(1<<(index%8)) != 0
+		}
+	}
+
+	return nil
+}
+
+// render darkens the tiles the viewing faction doesn't see, drawing all of them at once
+func (fw *FogOfWar) render(gc *GameContext, viewport *sdl.Rect) {
+	gm := fw.gameMap
+	fw.vertices = fw.vertices[:0]
+	fw.indices = fw.indices[:0]
+
+	for row := int32(0); row < gm.mapHeight; row++ {
+		for col := int32(0); col < gm.mapWidth; col++ {
+			var color sdl.Color
+			switch fw.GetVisibility(fw.viewingFaction, col, row) {
+			case Visible:
+				continue
+			case Explored:
+				color = fw.exploredColor
+			case Unexplored:
+				color = fw.unexploredColor
+			}
+
+			x, y := gm.TileToWorld(col, row)
+			tileRect := sdl.Rect{X: x, Y: y - gm.GetElevationOffset(gm.GetElevation(col, row)), W: gm.tileWidth, H: gm.tileHeight}
+			gc.Camera.TranslateSDLRect(&tileRect)
+			if !rectsOverlap(&tileRect, viewport) {
+				continue
+			}
+
+			fw.appendDiamond(&tileRect, color)
+		}
+	}
+
+	if len(fw.vertices) == 0 {
+		return
+	}
+
+	renderer := gc.GetRenderer()
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	renderer.RenderGeometry(nil, fw.vertices, fw.indices)
+}
+
+// appendDiamond adds the footprint of a tile (two triangles) to the fog geometry
+func (fw *FogOfWar) appendDiamond(tileRect *sdl.Rect, color sdl.Color) {
+	first := int32(len(fw.vertices))
+	left, top := float32(tileRect.X), float32(tileRect.Y)
+	width, height := float32(tileRect.W), float32(tileRect.H)
+
+	fw.vertices = append(fw.vertices,
+		sdl.Vertex{Position: sdl.FPoint{X: left + width/2, Y: top}, Color: color},
+		sdl.Vertex{Position: sdl.FPoint{X: left + width, Y: top + height/2}, Color: color},
+		sdl.Vertex{Position: sdl.FPoint{X: left + width/2, Y: top + height}, Color: color},
+		sdl.Vertex{Position: sdl.FPoint{X: left, Y: top + height/2}, Color: color},
+	)
+	fw.indices = append(fw.indices, first, first+1, first+2, first, first+2, first+3)
+}
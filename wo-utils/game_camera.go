@@ -1,6 +1,10 @@
 package woutils
 
-import "github.com/veandco/go-sdl2/sdl"
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
 
 type GameCamera struct {
 	translationX int32
@@ -57,3 +61,24 @@ func (gc *GameCamera) TranslateSDLRect(sdlRect *sdl.Rect) {
 	sdlRect.X += gc.translationX
 	sdlRect.Y += gc.translationY
 }
+
+// CenterOn moves the camera so the point (in world coordinates) is at the center of a screen of the given size
+func (gc *GameCamera) CenterOn(x, y, screenWidth, screenHeight int32) {
+	gc.translationX = int32(float32(screenWidth)/2/gc.zoom) - x
+	gc.translationY = int32(float32(screenHeight)/2/gc.zoom) - y
+}
+
+// ScreenToWorld converts a point of the screen (e.g. the mouse cursor) to world (map) coordinates,
+// undoing the zoom and the translation
+func (gc *GameCamera) ScreenToWorld(x, y int32) (int32, int32) {
+	worldX := float64(x)/float64(gc.zoom) - float64(gc.translationX)
+	worldY := float64(y)/float64(gc.zoom) - float64(gc.translationY)
+	return int32(math.Floor(worldX)), int32(math.Floor(worldY))
+}
+
+// WorldToScreen converts a point in world (map) coordinates to the screen, applying the translation and the zoom
+func (gc *GameCamera) WorldToScreen(x, y int32) (int32, int32) {
+	screenX := float32(x+gc.translationX) * gc.zoom
+	screenY := float32(y+gc.translationY) * gc.zoom
+	return int32(screenX), int32(screenY)
+}
@@ -1,3 +1,9 @@
+// Package woutils has the building blocks of the games: the GameContext and its main loop,
+// images, texts, audio, Tiled maps and the assets they share.
+//
+// The loaders ending in FromFs read from any fs.FS instead of the files of the operating system
+// (e.g. an embed.FS, a zip.Reader or a VirtualFS with mods). The files referenced by a file
+// (e.g. the tilesets of a map) are read from the same fs.FS
 package woutils
 
 import (
@@ -25,6 +31,16 @@ type GameContext struct {
 	shouldExit             bool
 	targetFramerate        uint32
 	lastFrameTime          uint64
+	lastUpdateTicks        uint64 // Start of the previous frame, to measure the time between frames
+	assets                 *AssetManager
+	mainThreadQueue        *mainThreadQueue // Functions queued by other goroutines (see RunOnMainThread)
+	transition             *Transition      // Covering the screen, see StartTransition
+	tweens                 []*Tween         // Playing, see PlayTween
+	timers                 *TimerGroup      // Used by After and Every
+	timerGroups            []*TimerGroup
+	isPaused               bool
+	timeScale              float64
+	gameTime               float64 // Milliseconds, see GetGameTime
 	Camera                 GameCamera
 }
 
@@ -41,6 +57,16 @@ func NewContext(gameName string) GameContext {
 		shouldExit:             false,
 		targetFramerate:        30,
 		lastFrameTime:          0,
+		lastUpdateTicks:        0,
+		assets:                 NewAssetManager(),
+		mainThreadQueue:        newMainThreadQueue(),
+		transition:             nil,
+		tweens:                 nil,
+		timers:                 &TimerGroup{},
+		timerGroups:            nil,
+		isPaused:               false,
+		timeScale:              1,
+		gameTime:               0,
 		Camera:                 NewGameCamera(),
 	}
 }
@@ -52,6 +78,7 @@ func (gc *GameContext) Start() {
 	if gc.renderer, err = sdl.CreateRenderer(gc.window.AsSDLWindow(), -1, sdl.RENDERER_ACCELERATED); err != nil {
 		log.Fatalf("Failed to create renderer: %s", err)
 	}
+	gc.assets.SetRenderer(gc.renderer)
 }
 
 func (gc *GameContext) GetTargetFramerate() uint32 {
@@ -79,6 +106,11 @@ func (gc *GameContext) GetRenderer() *sdl.Renderer {
 	return gc.renderer
 }
 
+// GetAssets returns the manager of the textures, fonts and audio chunks shared in the game
+func (gc *GameContext) GetAssets() *AssetManager {
+	return gc.assets
+}
+
 func (gc *GameContext) StopExecution() {
 	gc.shouldExit = true
 }
@@ -119,12 +151,19 @@ func (gc *GameContext) HandleEvent(event *sdl.Event) bool {
 			keepRunning = false
 		}
 	case *sdl.MouseMotionEvent:
+		if gc.transition != nil {
+			return keepRunning
+		}
 		for _, listener := range slices.Backward(gc.mouseMovementListeners) {
 			if listener(t.X, t.Y) {
 				return keepRunning
 			}
 		}
 	case *sdl.MouseButtonEvent:
+		// Releases are still sent, so presses made before the transition end (e.g. dragging the map)
+		if gc.transition != nil && t.State == sdl.PRESSED {
+			return keepRunning
+		}
 		for _, listener := range slices.Backward(gc.mouseClickListeners) {
 			// Listener returns true to stop iteration
 			if listener(t.X, t.Y, t.Button, t.State == sdl.PRESSED) {
@@ -140,6 +179,23 @@ func (gc *GameContext) AddRenderable(thingToRender Renderable) {
 	gc.renderQueue = append(gc.renderQueue, thingToRender)
 }
 
+// RemoveRenderable stops rendering something (e.g. the GameMap left in a transition)
+func (gc *GameContext) RemoveRenderable(thingToRender Renderable) {
+	if index := slices.Index(gc.renderQueue, thingToRender); index >= 0 {
+		gc.renderQueue = slices.Delete(gc.renderQueue, index, index+1)
+	}
+}
+
+// ReplaceRenderable renders newThing in the place of oldThing, keeping the order of the queue
+// (e.g. a new GameMap below the interface)
+func (gc *GameContext) ReplaceRenderable(oldThing, newThing Renderable) {
+	if index := slices.Index(gc.renderQueue, oldThing); index >= 0 {
+		gc.renderQueue[index] = newThing
+	} else {
+		gc.AddRenderable(newThing)
+	}
+}
+
 // Initialize the render zoom, scaling the renderer to the zoom value
 // This is useful inside a rendering function, to scale the renderer before rendering
 // other things
@@ -159,6 +215,23 @@ func (gc *GameContext) Render() {
 		panic("Renderer not initialized. Did you run Start()?")
 	}
 
+	// The midpoint of a transition may replace the scene, before it is drawn
+	if gc.transition != nil {
+		gc.transition.update()
+	}
+
+	gc.renderScene()
+
+	if gc.transition != nil {
+		gc.renderTransition()
+	}
+
+	// Atualiza a janela com o frame atual
+	gc.renderer.Present()
+}
+
+// renderScene clears the screen and draws the render queue
+func (gc *GameContext) renderScene() {
 	// Limpa a tela com uma cor (neste caso, preta)
 	if err := gc.renderer.SetDrawColor(20, 0, 20, 255); err != nil {
 		log.Fatalf("Falha ao definir cor de desenho: %s", err)
@@ -173,12 +246,17 @@ func (gc *GameContext) Render() {
 			renderable.Render(gc)
 		}
 	}
-
-	// Atualiza a janela com o frame atual
-	gc.renderer.Present()
 }
 
 func (gc *GameContext) Destroy() {
+	gc.mainThreadQueue.close()
+
+	if gc.transition != nil {
+		gc.transition.destroy()
+	}
+
+	gc.assets.Destroy()
+
 	if gc.renderer != nil {
 		gc.renderer.Destroy()
 	}
@@ -196,6 +274,25 @@ func (gc *GameContext) runDelay() {
 	sdl.Delay((1000 / gc.targetFramerate) - uint32(sdl.GetTicks64()-gc.lastFrameTime))
 }
 
+// update advances what is driven by the time between frames
+func (gc *GameContext) update() {
+	ticks := sdl.GetTicks64()
+	var realDeltaMs float64
+	if gc.lastUpdateTicks != 0 {
+		realDeltaMs = float64(ticks - gc.lastUpdateTicks)
+	}
+	gc.lastUpdateTicks = ticks
+
+	gameDeltaMs := realDeltaMs * gc.timeScale
+	if gc.isPaused {
+		gameDeltaMs = 0
+	}
+	gc.gameTime += gameDeltaMs
+
+	gc.updateTimers(gameDeltaMs, realDeltaMs)
+	gc.updateTweens(gameDeltaMs, realDeltaMs)
+}
+
 func (gc *GameContext) MainLoop() {
 	running := true
 
@@ -205,6 +302,8 @@ func (gc *GameContext) MainLoop() {
 			running = gc.HandleEvent(&event)
 		}
 
+		gc.update()
+		gc.runMainThreadTasks()
 		gc.Render()
 
 		gc.lastFrameTime = sdl.GetTicks64()
@@ -0,0 +1,140 @@
+package woutils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// mainThreadID identifies the thread running the main loop, see isMainThread
+var mainThreadID uint
+
+func init() {
+	// Package initialization always runs in the main thread, the one woengine locks for SDL
+	mainThreadID = sdl.CurrentThreadID()
+}
+
+// isMainThread reports whether the calling goroutine runs in the main thread
+func isMainThread() bool {
+	return sdl.CurrentThreadID() == mainThreadID
+}
+
+// mainThreadQueue keeps the functions other goroutines asked to run in the main thread
+type mainThreadQueue struct {
+	mutex    sync.Mutex
+	tasks    []func()
+	budget   time.Duration // Time the tasks may take each frame
+	closed   chan struct{} // Closed by GameContext.Destroy, the tasks queued will never run
+	isClosed bool
+}
+
+func newMainThreadQueue() *mainThreadQueue {
+	return &mainThreadQueue{
+		tasks:    nil,
+		budget:   4 * time.Millisecond,
+		closed:   make(chan struct{}),
+		isClosed: false,
+	}
+}
+
+// close drops the tasks queued and unblocks the goroutines waiting for them
+func (mq *mainThreadQueue) close() {
+	mq.mutex.Lock()
+	defer mq.mutex.Unlock()
+
+	if mq.isClosed {
+		return
+	}
+	mq.isClosed = true
+	mq.tasks = nil
+	close(mq.closed)
+}
+
+// RunOnMainThread queues task to run in the main thread, in the next frames (before rendering).
+// It can be called from any goroutine, and returns without waiting for task. Tasks run in the
+// order they were queued. Tasks queued after the context is destroyed never run
+func (gc *GameContext) RunOnMainThread(task func()) {
+	gc.mainThreadQueue.mutex.Lock()
+	defer gc.mainThreadQueue.mutex.Unlock()
+
+	if gc.mainThreadQueue.isClosed {
+		return
+	}
+	gc.mainThreadQueue.tasks = append(gc.mainThreadQueue.tasks, task)
+}
+
+// RunOnMainThreadSync runs task in the main thread and waits until it returns. Called from the main
+// thread, it runs task right away. If the context is destroyed before task runs, it returns without running it
+func (gc *GameContext) RunOnMainThreadSync(task func()) {
+	if isMainThread() {
+		task()
+		return
+	}
+
+	done := make(chan struct{})
+	gc.RunOnMainThread(func() {
+		defer close(done)
+		task()
+	})
+
+	select {
+	case <-done:
+	case <-gc.mainThreadQueue.closed:
+	}
+}
+
+// CallOnMainThread runs task in the main thread and returns its result (see RunOnMainThreadSync).
+// If the context is destroyed before task runs, it returns the zero value of T
+//
+//	texture := CallOnMainThread(context, func() *sdl.Texture { ... })
+func CallOnMainThread[T any](context *GameContext, task func() T) T {
+	var result T
+	context.RunOnMainThreadSync(func() {
+		result = task()
+	})
+	return result
+}
+
+// SetMainThreadBudget sets how long the queued tasks may run each frame. The tasks left run in
+// the next frames, so a burst of tasks does not stall the rendering. At least one task runs each frame
+func (gc *GameContext) SetMainThreadBudget(budget time.Duration) {
+	gc.mainThreadQueue.mutex.Lock()
+	defer gc.mainThreadQueue.mutex.Unlock()
+
+	gc.mainThreadQueue.budget = budget
+}
+
+// GetPendingMainThreadTasks returns how many queued tasks did not run yet
+func (gc *GameContext) GetPendingMainThreadTasks() int {
+	gc.mainThreadQueue.mutex.Lock()
+	defer gc.mainThreadQueue.mutex.Unlock()
+
+	return len(gc.mainThreadQueue.tasks)
+}
+
+// runMainThreadTasks runs the queued tasks until the budget of the frame is used
+func (gc *GameContext) runMainThreadTasks() {
+	queue := gc.mainThreadQueue
+	start := time.Now()
+
+	for {
+		queue.mutex.Lock()
+		if len(queue.tasks) == 0 {
+			queue.mutex.Unlock()
+			return
+		}
+		task := queue.tasks[0]
+		queue.tasks[0] = nil
+		queue.tasks = queue.tasks[1:]
+		budget := queue.budget
+		queue.mutex.Unlock()
+
+		// Tasks may queue other tasks, so the queue is unlocked while they run
+		task()
+
+		if time.Since(start) >= budget {
+			return
+		}
+	}
+}
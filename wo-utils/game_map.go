@@ -1,25 +1,32 @@
 package woutils
 
 import (
+	"io/fs"
 	"log"
+	"slices"
 
 	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
 type GameMapLayer struct {
-	layerName string
-	tiles     []int32 // Tiles ID
+	layerName  string
+	tiles      []uint32 // Tiles GID (tile ID plus the Tiled flip flags)
+	properties *TmxProperties
+	elevation  int32   // Height level of the layer, used by the tiles without their own height
+	elevations []int32 // Height level of each tile
 }
 
 type GameMapTileSet struct {
 	minTileId         int32
 	maxTileId         int32
 	texture           *sdl.Texture
+	fsys              fs.FS // Where the texture is read from
 	textureSourcePath string
 	columns           int32
 	tileWidth         int32
 	tileHeight        int32
+	tiles             map[int32]*TsxTile // Extra data of the tiles (properties, collision shapes), by ID local to the tileset
 }
 
 func (gmt *GameMapTileSet) getTileRect(tileId int32) sdl.Rect {
@@ -42,22 +49,57 @@ type GameMap struct {
 	mapHeight  int32
 	layers     []GameMapLayer
 	tileSets   []*GameMapTileSet // Maps tileset firstgid to tileset
+	wangSets   []*GameMapWangSet // Terrain sets of all the tilesets
+	tiledMap   TiledMap          // Loaded data, kept to save the map back with everything the engine doesn't use
+	// Called after a tile is changed with SetTile, FillLayer or FillRect.
+	// Used by anything that keeps data derived from the tiles (e.g. pre-rendered textures)
+	tileChangeListeners []tileChangeListener
+	nextListenerId      int
+	depthSortedLayer    int // Layer drawn together with the entities, sorted by depth. -1 if none
+	entities            []MapEntity
+	sortedEntities      []MapEntity // Reused every frame to sort the entities without allocating
+	fogOfWar            *FogOfWar   // Drawn over the map and the entities. nil if none
+	heightStep          int32       // Pixels each height level moves the tiles up
+	minElevation        int32       // Bounds of the height levels used in the map
+	maxElevation        int32
+	assets              *AssetManager // Shares the tileset textures
 	womixins.HideMixin
 }
 
 func NewGameMap(context *GameContext, mapName string, tmxFilePath string) GameMap {
-	tileMap := NewTiledMap(tmxFilePath)
+	return newGameMapFromTiledMap(context, NewTiledMap(tmxFilePath))
+}
+
+// NewGameMapFromFs creates a map from a TMX file of fsys.
+// Its tilesets and images are read from fsys too
+func NewGameMapFromFs(context *GameContext, mapName string, fsys fs.FS, tmxFilePath string) GameMap {
+	return newGameMapFromTiledMap(context, NewTiledMapFromFs(fsys, tmxFilePath))
+}
 
+// newGameMapFromTiledMap creates the map from TMX data, loaded from a file or built in code (see MapBuilder)
+func newGameMapFromTiledMap(context *GameContext, tileMap TiledMap) GameMap {
 	tileSets := make([]*GameMapTileSet, len(tileMap.TmxMap.TileSets))
+	var wangSets []*GameMapWangSet
 	for index, tileSet := range tileMap.TmxMap.TileSets {
 		tileSets[index] = &GameMapTileSet{
 			minTileId:         int32(tileSet.FirstGid),
 			maxTileId:         int32(tileSet.FirstGid + tileSet.TsxData.TileCount - 1),
 			texture:           nil,
-			textureSourcePath: AppendOnPath(GetDirFromPath(tileSet.TsxPath), tileSet.TsxData.Image.Source),
+			fsys:              tileMap.fsys,
+			textureSourcePath: ResolvePath(tileSet.TsxPath, tileSet.TsxData.Image.Source),
 			tileWidth:         int32(tileSet.TsxData.TileWidth),
 			tileHeight:        int32(tileSet.TsxData.TileHeight),
 			columns:           int32(tileSet.TsxData.Columns),
+			tiles:             make(map[int32]*TsxTile, len(tileSet.TsxData.Tiles)),
+		}
+
+		for tileIndex := range tileSet.TsxData.Tiles {
+			tile := &tileSet.TsxData.Tiles[tileIndex]
+			tileSets[index].tiles[int32(tile.Id)] = tile
+		}
+
+		for wangSetIndex := range tileSet.TsxData.WangSets {
+			wangSets = append(wangSets, newGameMapWangSet(&tileSet.TsxData.WangSets[wangSetIndex], int32(tileSet.FirstGid)))
 		}
 	}
 
@@ -65,20 +107,28 @@ func NewGameMap(context *GameContext, mapName string, tmxFilePath string) GameMa
 	for layerIndex := range tileMap.TmxMap.Layers {
 		layer := tileMap.TmxMap.Layers[layerIndex] // Using pointer to update the original struct
 
+		// Every layer must cover the whole map, so tiles can be read and changed by column and row
+		tiles := make([]uint32, tileMap.TmxMap.Width*tileMap.TmxMap.Height)
+		if len(layer.Data.Tiles) != len(tiles) {
+			log.Printf("Layer %s has %d tiles, expected %d. Missing tiles will be empty\n", layer.Name, len(layer.Data.Tiles), len(tiles))
+		}
+		copy(tiles, layer.Data.Tiles)
+
 		layers[layerIndex] = GameMapLayer{
-			layerName: layer.Name,
-			tiles:     layer.Data.Tiles,
+			layerName:  layer.Name,
+			tiles:      tiles,
+			properties: layer.Properties,
 		}
 	}
 
 	for tileSetIndex := range tileSets {
 		tileSet := tileSets[tileSetIndex] // Using pointer to update the original struct
-		if err := loadTextures(context.GetRenderer(), tileSet); err != nil {
+		if err := loadTextures(context.GetAssets(), tileSet); err != nil {
 			log.Fatalf("Failed to load textures for tileset ID %d, image_source: %s\n", tileSetIndex, tileSet.textureSourcePath)
 		}
 	}
 
-	return GameMap{
+	gameMap := GameMap{
 		HideMixin:  womixins.NewHideMixin(),
 		tileWidth:  int32(tileMap.TmxMap.TileWidth),
 		tileHeight: int32(tileMap.TmxMap.TileHeight),
@@ -86,16 +136,26 @@ func NewGameMap(context *GameContext, mapName string, tmxFilePath string) GameMa
 		mapHeight:  int32(tileMap.TmxMap.Height),
 		layers:     layers,
 		tileSets:   tileSets,
+		wangSets:   wangSets,
+		tiledMap:   tileMap,
+		// No layer is sorted by default, so entities are drawn over the map
+		depthSortedLayer: -1,
+		entities:         nil,
+		heightStep:       int32(tileMap.TmxMap.Properties.GetInt("heightstep", tileMap.TmxMap.TileHeight/2)),
+		assets:           context.GetAssets(),
 	}
+	gameMap.initElevations()
+
+	return gameMap
 }
 
-func loadTextures(renderer *sdl.Renderer, tileSet *GameMapTileSet) error {
+func loadTextures(assets *AssetManager, tileSet *GameMapTileSet) error {
 	if tileSet.texture != nil {
 		log.Printf("Texture already loaded for tileset %s\n", tileSet.textureSourcePath)
 		return nil
 	}
 
-	texture, err := LoadTexture(renderer, tileSet.textureSourcePath)
+	texture, err := assets.AcquireTextureFromFs(tileSet.fsys, tileSet.textureSourcePath)
 	if err != nil {
 		return err
 	}
@@ -107,7 +167,8 @@ func loadTextures(renderer *sdl.Renderer, tileSet *GameMapTileSet) error {
 func (gm *GameMap) Destroy() {
 	for _, tileSet := range gm.tileSets {
 		if tileSet.texture != nil {
-			tileSet.texture.Destroy()
+			gm.assets.ReleaseTexture(tileSet.texture)
+			tileSet.texture = nil
 		}
 	}
 }
@@ -133,44 +194,119 @@ func (gm *GameMap) getTileCoordinates(index int) (x, y int32) {
 	return x, y
 }
 
-func (gm *GameMap) Render(gc *GameContext) {
-	var currentTileset *GameMapTileSet
-	renderer := gc.GetRenderer()
+// getTileRenderRect returns where the tile is drawn, in map coordinates.
+// Tiles bigger than the map tiles (e.g. trees and walls) are aligned by the bottom center of the tile
+func (gm *GameMap) getTileRenderRect(col, row int32, tileSet *GameMapTileSet) sdl.Rect {
+	x, y := gm.getTileCoordinates(int(row*gm.mapWidth + col))
 
-	gc.InitRenderZoom()
-	defer gc.ResetRenderZoom()
+	return sdl.Rect{
+		X: x + (gm.tileWidth-tileSet.tileWidth)/2,
+		Y: y + gm.tileHeight - tileSet.tileHeight,
+		W: tileSet.tileWidth,
+		H: tileSet.tileHeight,
+	}
+}
 
+func (gm *GameMap) getRenderViewport(gc *GameContext) sdl.Rect {
 	offsetX, offsetY := gm.tileWidth, gm.tileHeight
-	viewport := renderer.GetViewport()
+	viewport := gc.GetRenderer().GetViewport()
 	viewport.X = viewport.X - offsetX
 	viewport.Y = viewport.Y - offsetY
 	viewport.W = viewport.W + offsetX
 	viewport.H = viewport.H + offsetY
 
-	for _, layer := range gm.layers {
-		for i, tileID := range layer.tiles {
-			if tileID == 0 {
-				continue
-			}
-
-			if currentTileset = gm.getTilesetFromTileId(tileID); currentTileset == nil {
-				log.Fatalln("Couldn't find tileset. Are the tilemaps and tilesets properly configured?")
-			}
-
-			x, y := gm.getTileCoordinates(i)
-			tileRect := sdl.Rect{
-				X: x,
-				Y: y,
-				W: gm.tileWidth,
-				H: gm.tileHeight,
-			}
-			gc.Camera.TranslateSDLRect(&tileRect)
-
-			// If tile will be rendered in visible area of the screen
-			if rectsOverlap(&tileRect, &viewport) {
-				tileSetRect := currentTileset.getTileRect(tileID)
-				renderer.Copy(currentTileset.texture, &tileSetRect, &tileRect)
-			}
+	return viewport
+}
+
+func (gm *GameMap) renderTile(gc *GameContext, viewport *sdl.Rect, layer int, col, row int32) {
+	gid := gm.layers[layer].tiles[row*gm.mapWidth+col]
+	tileID := TileIdFromGid(gid)
+	if tileID == 0 {
+		return
+	}
+
+	currentTileset := gm.getTilesetFromTileId(tileID)
+	if currentTileset == nil {
+		log.Fatalln("Couldn't find tileset. Are the tilemaps and tilesets properly configured?")
+	}
+
+	tileRect := gm.getTileRenderRect(col, row, currentTileset)
+	tileRect.Y -= gm.GetElevationOffset(gm.layers[layer].elevations[row*gm.mapWidth+col])
+	gc.Camera.TranslateSDLRect(&tileRect)
+
+	// If tile will be rendered in visible area of the screen
+	if rectsOverlap(&tileRect, viewport) {
+		renderer := gc.GetRenderer()
+		tileSetRect := currentTileset.getTileRect(tileID)
+		if gid&TILE_FLIP_FLAGS == 0 {
+			renderer.Copy(currentTileset.texture, &tileSetRect, &tileRect)
+		} else {
+			angle, flip := tileFlipToSDL(gid)
+			renderer.CopyEx(currentTileset.texture, &tileSetRect, &tileRect, angle, nil, flip)
+		}
+	}
+}
+
+func (gm *GameMap) renderLayer(gc *GameContext, viewport *sdl.Rect, layer int) {
+	for row := int32(0); row < gm.mapHeight; row++ {
+		for col := int32(0); col < gm.mapWidth; col++ {
+			gm.renderTile(gc, viewport, layer, col, row)
 		}
 	}
 }
+
+func (gm *GameMap) Render(gc *GameContext) {
+	gc.InitRenderZoom()
+	defer gc.ResetRenderZoom()
+
+	viewport := gm.getRenderViewport(gc)
+
+	for layer := range gm.layers {
+		if layer == gm.depthSortedLayer {
+			gm.renderDepthSortedLayer(gc, &viewport, layer)
+		} else {
+			gm.renderLayer(gc, &viewport, layer)
+		}
+	}
+
+	// Without a depth sorted layer, the entities are drawn over the whole map
+	if gm.depthSortedLayer < 0 || gm.depthSortedLayer >= len(gm.layers) {
+		gm.renderEntities(gc)
+	}
+
+	if gm.fogOfWar != nil {
+		gm.fogOfWar.render(gc, &viewport)
+	}
+}
+
+// SetFogOfWar chooses the fog drawn over the map, or nil to draw no fog
+func (gm *GameMap) SetFogOfWar(fog *FogOfWar) {
+	gm.fogOfWar = fog
+}
+
+func (gm *GameMap) GetFogOfWar() *FogOfWar {
+	return gm.fogOfWar
+}
+
+// GetObjectGroup returns the object group (object layer) named name, or nil if there is no such group.
+// Changes to the objects are kept when the map is saved.
+func (gm *GameMap) GetObjectGroup(name string) *TmxObjectGroup {
+	return gm.tiledMap.TmxMap.GetObjectGroup(name)
+}
+
+func (gm *GameMap) GetObjectGroups() []TmxObjectGroup {
+	return gm.tiledMap.TmxMap.ObjectGroups
+}
+
+// Save writes the map, including the tiles changed at runtime, to a TMX file. Layers stored as XML
+// tile elements or as chunks of infinite maps can't be encoded again, so they are saved as loaded
+func (gm *GameMap) Save(path string, encoding TmxEncoding) error {
+	for layerIndex := range gm.layers {
+		if gm.tiledMap.TmxMap.Layers[layerIndex].Data.Tiles == nil {
+			continue
+		}
+		gm.tiledMap.TmxMap.Layers[layerIndex].Data.Tiles = slices.Clone(gm.layers[layerIndex].tiles)
+	}
+
+	return gm.tiledMap.Save(path, encoding)
+}
@@ -0,0 +1,118 @@
+package woutils
+
+// Tiles can be raised above the ground (cliffs, stairs, bridges...) with the integer property "height",
+// set on the tile in the tileset or on the layer (for every tile of the layer). Each height level
+// moves the tile up by the height step of the map, which is the map property "heightstep"
+// (in pixels), or half the tile height by default.
+
+// ElevatedEntity is a MapEntity that chooses its own height level (e.g. units flying or walking under a bridge).
+// Other entities stand on the topmost tile under their base position.
+type ElevatedEntity interface {
+	MapEntity
+	GetElevation() int32
+}
+
+// initElevations reads the height level of every tile, so rendering doesn't parse properties every frame
+func (gm *GameMap) initElevations() {
+	gm.minElevation, gm.maxElevation = 0, 0
+
+	for layer := range gm.layers {
+		gameMapLayer := &gm.layers[layer]
+		gameMapLayer.elevation = int32(gameMapLayer.properties.GetInt("height", 0))
+		gameMapLayer.elevations = make([]int32, len(gameMapLayer.tiles))
+
+		for index, gid := range gameMapLayer.tiles {
+			gm.updateElevation(layer, index, gid)
+		}
+	}
+}
+
+func (gm *GameMap) updateElevation(layer int, index int, gid uint32) {
+	gameMapLayer := &gm.layers[layer]
+
+	elevation := gameMapLayer.elevation
+	if gid != 0 {
+		elevation = int32(gm.GetTileProperties(gid).GetInt("height", int(elevation)))
+	}
+	gameMapLayer.elevations[index] = elevation
+
+	// Only grow, they are the bounds searched when picking tiles
+	gm.minElevation = min(gm.minElevation, elevation)
+	gm.maxElevation = max(gm.maxElevation, elevation)
+}
+
+// SetHeightStep changes how many pixels each height level moves the tiles up
+func (gm *GameMap) SetHeightStep(pixels int32) {
+	gm.heightStep = pixels
+}
+
+func (gm *GameMap) GetHeightStep() int32 {
+	return gm.heightStep
+}
+
+// GetElevationOffset returns how many pixels a height level is above the ground
+func (gm *GameMap) GetElevationOffset(elevation int32) int32 {
+	return elevation * gm.heightStep
+}
+
+// GetTileElevation returns the height level of the tile in the layer
+func (gm *GameMap) GetTileElevation(layer int, col, row int32) int32 {
+	gm.checkLayer(layer)
+	if !gm.IsInside(col, row) {
+		return 0
+	}
+	return gm.layers[layer].elevations[row*gm.mapWidth+col]
+}
+
+// GetElevation returns the height level of the topmost tile (of any layer) at the column and row,
+// where entities stand. Empty columns are at the ground (0)
+func (gm *GameMap) GetElevation(col, row int32) int32 {
+	if !gm.IsInside(col, row) {
+		return 0
+	}
+
+	index := row*gm.mapWidth + col
+	elevation, found := int32(0), false
+	for layer := range gm.layers {
+		if gm.layers[layer].tiles[index] == 0 {
+			continue
+		}
+
+		if tileElevation := gm.layers[layer].elevations[index]; !found || tileElevation > elevation {
+			elevation, found = tileElevation, true
+		}
+	}
+	return elevation
+}
+
+// GetEntityElevation returns the height level where the entity is drawn
+func (gm *GameMap) GetEntityElevation(entity MapEntity) int32 {
+	if elevated, ok := entity.(ElevatedEntity); ok {
+		return elevated.GetElevation()
+	}
+
+	return gm.GetElevation(gm.WorldToTile(entity.GetBasePosition()))
+}
+
+// PickTile returns the tile whose top surface contains the point (in map coordinates), considering
+// the height of the tiles. When raised tiles overlap, the one in front wins.
+// Points over no tile return the ground tile, which may be outside the map (check it with IsInside)
+func (gm *GameMap) PickTile(x, y int32) (col, row int32) {
+	col, row = gm.WorldToTile(x, y)
+	bestDepth, found := int32(0), false
+
+	for elevation := gm.minElevation; elevation <= gm.maxElevation; elevation++ {
+		candidateCol, candidateRow := gm.WorldToTile(x, y+gm.GetElevationOffset(elevation))
+		if !gm.IsInside(candidateCol, candidateRow) || gm.GetElevation(candidateCol, candidateRow) != elevation {
+			continue
+		}
+
+		// Same depth: the higher tile (found later) is drawn over the lower one
+		if depth := candidateCol + candidateRow; !found || depth >= bestDepth {
+			col, row = candidateCol, candidateRow
+			bestDepth, found = depth, true
+		}
+	}
+
+	return col, row
+}
@@ -0,0 +1,233 @@
+package woutils
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// MapEntity is something drawn over a GameMap (characters, items, effects...).
+// Inside the depth sorted layer, entities are drawn between the tiles, so they
+// can pass behind tall tiles like walls and trees.
+//
+// The entity is rendered with the camera zoom already applied, and should apply
+// the camera translation to its map coordinates (see GameCamera.ApplyTranslation).
+// Entities on raised tiles are moved up by the map, through the camera translation.
+type MapEntity interface {
+	Renderable
+	// GetBasePosition returns the point where the entity touches the ground, in map coordinates,
+	// without the height of the tile it stands on
+	GetBasePosition() (x, y int32)
+}
+
+// AddEntity registers an entity to be rendered with the map
+func (gm *GameMap) AddEntity(entity MapEntity) {
+	gm.entities = append(gm.entities, entity)
+}
+
+func (gm *GameMap) RemoveEntity(entity MapEntity) {
+	gm.entities = slices.DeleteFunc(gm.entities, func(e MapEntity) bool {
+		return e == entity
+	})
+}
+
+// SetDepthSortedLayer chooses the layer in which the tiles and the entities are drawn
+// sorted by their footprint. Use -1 to draw the entities over the whole map.
+func (gm *GameMap) SetDepthSortedLayer(layer int) {
+	if layer >= 0 {
+		gm.checkLayer(layer)
+	}
+	gm.depthSortedLayer = layer
+}
+
+func (gm *GameMap) GetDepthSortedLayer() int {
+	return gm.depthSortedLayer
+}
+
+// TileToWorld returns the top left corner of the rectangle containing the tile, in map coordinates
+func (gm *GameMap) TileToWorld(col, row int32) (x, y int32) {
+	return gm.getTileCoordinates(int(row*gm.mapWidth + col))
+}
+
+// GetTileCenter returns the center of the tile footprint (diamond), in map coordinates
+func (gm *GameMap) GetTileCenter(col, row int32) (x, y int32) {
+	x, y = gm.TileToWorld(col, row)
+	return x + gm.tileWidth/2, y + gm.tileHeight/2
+}
+
+// WorldToTile returns the tile whose footprint contains the point (in map coordinates).
+// The result may be outside the map, check it with IsInside.
+func (gm *GameMap) WorldToTile(x, y int32) (col, row int32) {
+	// Inverse of getTileCoordinates, relative to the top corner of the tile (0, 0)
+	relativeX := float64(x - gm.tileWidth/2)
+	doubleY := float64(2 * y)
+
+	col = int32(math.Floor((doubleY + relativeX) / float64(gm.tileWidth)))
+	row = int32(math.Floor((doubleY - relativeX) / float64(2*gm.tileHeight)))
+	return col, row
+}
+
+// ScreenToTile returns the tile under a point of the screen (e.g. the mouse cursor), considering
+// the height of the tiles (see PickTile)
+func (gm *GameMap) ScreenToTile(gc *GameContext, x, y int32) (col, row int32) {
+	return gm.PickTile(gc.Camera.ScreenToWorld(x, y))
+}
+
+// ObjectToTile converts the position of a TMX object to (fractional) tile coordinates.
+// In isometric maps, Tiled measures both axes of the objects in units of the tile height
+func (gm *GameMap) ObjectToTile(x, y float64) (col, row float64) {
+	return x / float64(gm.tileHeight), y / float64(gm.tileHeight)
+}
+
+// GroundToWorld converts ground coordinates (the coordinates of the TMX objects, where each tile
+// is a square with the side of the tile height) to map coordinates
+func (gm *GameMap) GroundToWorld(x, y float64) (float64, float64) {
+	halfTileWidth := float64(gm.tileWidth) / 2
+	col := x / float64(gm.tileHeight)
+
+	// Same projection of getTileCoordinates, from the top corner of the tile (0, 0)
+	return col*halfTileWidth - y + halfTileWidth, (col*halfTileWidth + y) / 2
+}
+
+// WorldToGround converts map coordinates to ground coordinates (see GroundToWorld)
+func (gm *GameMap) WorldToGround(x, y float64) (float64, float64) {
+	halfTileWidth := float64(gm.tileWidth) / 2
+	relativeX := x - halfTileWidth
+
+	projectedCol := (2*y + relativeX) / 2
+	groundY := (2*y - relativeX) / 2
+	return projectedCol / halfTileWidth * float64(gm.tileHeight), groundY
+}
+
+// GetTileImageRect returns where the image of the tile is drawn when placed at the column and row, in map coordinates
+func (gm *GameMap) GetTileImageRect(col, row int32, gid uint32) sdl.Rect {
+	tileSet := gm.getTilesetFromTileId(TileIdFromGid(gid))
+	if tileSet == nil {
+		x, y := gm.TileToWorld(col, row)
+		return sdl.Rect{X: x, Y: y, W: gm.tileWidth, H: gm.tileHeight}
+	}
+
+	return gm.getTileRenderRect(col, row, tileSet)
+}
+
+func (gm *GameMap) getSortedEntities() []MapEntity {
+	gm.sortedEntities = append(gm.sortedEntities[:0], gm.entities...)
+	slices.SortStableFunc(gm.sortedEntities, func(a, b MapEntity) int {
+		_, aY := a.GetBasePosition()
+		_, bY := b.GetBasePosition()
+		return cmp.Or(cmp.Compare(aY, bY), cmp.Compare(gm.GetEntityElevation(a), gm.GetEntityElevation(b)))
+	})
+
+	return gm.sortedEntities
+}
+
+// isEntityInFog reports whether the fog of war hides the entity, which is drawn only
+// while its tile is visible to the viewing faction
+func (gm *GameMap) isEntityInFog(entity MapEntity) bool {
+	if gm.fogOfWar == nil {
+		return false
+	}
+
+	col, row := gm.WorldToTile(entity.GetBasePosition())
+	return !gm.fogOfWar.IsVisible(gm.fogOfWar.viewingFaction, col, row)
+}
+
+func (gm *GameMap) renderEntity(gc *GameContext, entity MapEntity) {
+	if !entity.IsVisible() || gm.isEntityInFog(entity) {
+		return
+	}
+
+	// The entity applies the camera translation itself, so it is moved up to its height through the camera
+	offset := gm.GetElevationOffset(gm.GetEntityElevation(entity))
+	gc.Camera.Translate(0, -offset)
+	entity.Render(gc)
+	gc.Camera.Translate(0, offset)
+}
+
+func (gm *GameMap) renderEntities(gc *GameContext) {
+	for _, entity := range gm.getSortedEntities() {
+		gm.renderEntity(gc, entity)
+	}
+}
+
+// PickableEntity is a MapEntity with its own shape for PickEntity (e.g. the rectangle of its sprite).
+// Other entities are picked by the footprint of the tile under their base position.
+type PickableEntity interface {
+	MapEntity
+	// ContainsPoint reports whether the point (in map coordinates, without the height of the entity) hits the entity
+	ContainsPoint(x, y int32) bool
+}
+
+// PickEntity returns the entity drawn under the point (in map coordinates), considering the height
+// where each entity is drawn. When entities overlap, the one drawn in front wins. Returns nil if none
+func (gm *GameMap) PickEntity(x, y int32) MapEntity {
+	entities := gm.getSortedEntities()
+
+	// From front to back, the reverse of the drawing order
+	for index := len(entities) - 1; index >= 0; index-- {
+		entity := entities[index]
+		if !entity.IsVisible() || gm.isEntityInFog(entity) {
+			continue
+		}
+
+		groundY := y + gm.GetElevationOffset(gm.GetEntityElevation(entity))
+		if pickable, ok := entity.(PickableEntity); ok {
+			if pickable.ContainsPoint(x, groundY) {
+				return entity
+			}
+			continue
+		}
+
+		col, row := gm.WorldToTile(x, groundY)
+		if entityCol, entityRow := gm.WorldToTile(entity.GetBasePosition()); entityCol == col && entityRow == row {
+			return entity
+		}
+	}
+
+	return nil
+}
+
+// ScreenToEntity returns the entity under a point of the screen (e.g. the mouse cursor), see PickEntity
+func (gm *GameMap) ScreenToEntity(gc *GameContext, x, y int32) MapEntity {
+	return gm.PickEntity(gc.Camera.ScreenToWorld(x, y))
+}
+
+// isEntityBehindTile reports whether the entity must be drawn before the tile. Besides being behind it,
+// the entity is drawn after the tiles it stands on (the tiles of its column up to its height)
+func (gm *GameMap) isEntityBehindTile(entity MapEntity, layer int, col, row int32) bool {
+	entityX, entityY := entity.GetBasePosition()
+	if _, tileDepth := gm.GetTileCenter(col, row); entityY >= tileDepth {
+		return false
+	}
+
+	if entityCol, entityRow := gm.WorldToTile(entityX, entityY); entityCol == col && entityRow == row {
+		return gm.GetTileElevation(layer, col, row) > gm.GetEntityElevation(entity)
+	}
+	return true
+}
+
+// renderDepthSortedLayer draws the tiles from back to front (one diagonal of the map at a time),
+// drawing each entity right before the first tile whose footprint is in front of it
+func (gm *GameMap) renderDepthSortedLayer(gc *GameContext, viewport *sdl.Rect, layer int) {
+	entities := gm.getSortedEntities()
+	nextEntity := 0
+
+	for diagonal := int32(0); diagonal <= gm.mapWidth+gm.mapHeight-2; diagonal++ {
+		for col := max(0, diagonal-gm.mapHeight+1); col <= min(diagonal, gm.mapWidth-1); col++ {
+			row := diagonal - col
+
+			for nextEntity < len(entities) && gm.isEntityBehindTile(entities[nextEntity], layer, col, row) {
+				gm.renderEntity(gc, entities[nextEntity])
+				nextEntity++
+			}
+
+			gm.renderTile(gc, viewport, layer, col, row)
+		}
+	}
+
+	for ; nextEntity < len(entities); nextEntity++ {
+		gm.renderEntity(gc, entities[nextEntity])
+	}
+}
@@ -0,0 +1,93 @@
+package woutils
+
+import (
+	"testing"
+
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
+)
+
+type testEntity struct {
+	x, y int32
+	womixins.HideMixin
+}
+
+func (te *testEntity) Render(gc *GameContext) {}
+
+func (te *testEntity) GetBasePosition() (x, y int32) {
+	return te.x, te.y
+}
+
+// newTestElevationMap creates a 3x3 map of 64x32 tiles, where the center tile is raised 2 levels of 16 pixels
+// (covering the tile behind it, (0, 0))
+func newTestElevationMap() *GameMap {
+	gameMap := &GameMap{tileWidth: 64, tileHeight: 32, mapWidth: 3, mapHeight: 3, heightStep: 16}
+	gameMap.layers = []GameMapLayer{{tiles: make([]uint32, 9), elevations: make([]int32, 9)}}
+	for index := range gameMap.layers[0].tiles {
+		gameMap.layers[0].tiles[index] = 1
+	}
+	gameMap.layers[0].elevations[4] = 2
+	gameMap.maxElevation = 2
+	return gameMap
+}
+
+func newTestEntity(gameMap *GameMap, col, row int32) *testEntity {
+	x, y := gameMap.GetTileCenter(col, row)
+	return &testEntity{x: x, y: y, HideMixin: womixins.NewHideMixin()}
+}
+
+func TestPickEntity(t *testing.T) {
+	gameMap := newTestElevationMap()
+	ground := newTestEntity(gameMap, 2, 2)
+	raised := newTestEntity(gameMap, 1, 1)
+	behind := newTestEntity(gameMap, 2, 0)
+	front := newTestEntity(gameMap, 2, 0)
+	hidden := newTestEntity(gameMap, 0, 2)
+	hidden.Hide()
+	for _, entity := range []MapEntity{ground, raised, behind, front, hidden} {
+		gameMap.AddEntity(entity)
+	}
+
+	groundX, groundY := gameMap.GetTileCenter(2, 2)
+	raisedX, raisedY := gameMap.GetTileCenter(1, 1)
+	sharedX, sharedY := gameMap.GetTileCenter(2, 0)
+	hiddenX, hiddenY := gameMap.GetTileCenter(0, 2)
+
+	tests := []struct {
+		name     string
+		x, y     int32
+		expected MapEntity
+	}{
+		{"on the ground", groundX, groundY, ground},
+		{"on a raised tile", raisedX, raisedY - 32, raised},
+		{"under a raised entity", raisedX, raisedY, nil},
+		{"same position, the last added is in front", sharedX, sharedY, front},
+		{"hidden", hiddenX, hiddenY, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if picked := gameMap.PickEntity(test.x, test.y); picked != test.expected {
+				t.Fatalf("picked = %v, expected %v", picked, test.expected)
+			}
+		})
+	}
+}
+
+func TestPickEntityInFog(t *testing.T) {
+	gameMap := newTestElevationMap()
+	seen := newTestEntity(gameMap, 2, 2)
+	unseen := newTestEntity(gameMap, 0, 2)
+	gameMap.AddEntity(seen)
+	gameMap.AddEntity(unseen)
+
+	fog := NewFogOfWar(gameMap)
+	fog.SetViewingFaction("player")
+	fog.UpdateVisibility("player", []Viewer{{Col: 2, Row: 2, Radius: 0}})
+
+	if picked := gameMap.PickEntity(gameMap.GetTileCenter(2, 2)); picked != seen {
+		t.Fatalf("picked = %v, expected the entity on the visible tile", picked)
+	}
+	if picked := gameMap.PickEntity(gameMap.GetTileCenter(0, 2)); picked != nil {
+		t.Fatalf("picked = %v, expected nothing on the tile under the fog", picked)
+	}
+}
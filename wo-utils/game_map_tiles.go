@@ -0,0 +1,258 @@
+package woutils
+
+import (
+	"log"
+	"slices"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Tiled stores the flip state of a tile in the highest bits of its GID
+const (
+	TILE_FLIPPED_HORIZONTALLY  uint32 = 0x80000000
+	TILE_FLIPPED_VERTICALLY    uint32 = 0x40000000
+	TILE_FLIPPED_DIAGONALLY    uint32 = 0x20000000
+	TILE_ROTATED_HEXAGONAL_120 uint32 = 0x10000000
+
+	TILE_FLIP_FLAGS uint32 = TILE_FLIPPED_HORIZONTALLY | TILE_FLIPPED_VERTICALLY | TILE_FLIPPED_DIAGONALLY | TILE_ROTATED_HEXAGONAL_120
+)
+
+// TileIdFromGid removes the flip flags from a GID, returning the tile ID used by the tilesets
+func TileIdFromGid(gid uint32) int32 {
+	return int32(gid &^ TILE_FLIP_FLAGS)
+}
+
+// TileGid builds a GID from a tile ID and its flip flags
+func TileGid(tileId int32, flipFlags uint32) uint32 {
+	return uint32(tileId) | (flipFlags & TILE_FLIP_FLAGS)
+}
+
+// tileFlipToSDL converts the Tiled flip flags to the rotation and flip used by renderer.CopyEx.
+// Tiled applies the diagonal flip first, which is the same as a 90 degrees rotation over a vertical flip.
+func tileFlipToSDL(gid uint32) (angle float64, flip sdl.RendererFlip) {
+	horizontal := gid&TILE_FLIPPED_HORIZONTALLY != 0
+	vertical := gid&TILE_FLIPPED_VERTICALLY != 0
+
+	if gid&TILE_FLIPPED_DIAGONALLY != 0 {
+		angle = 90
+		horizontal, vertical = vertical, !horizontal
+	}
+
+	flip = sdl.FLIP_NONE
+	if horizontal {
+		flip |= sdl.FLIP_HORIZONTAL
+	}
+	if vertical {
+		flip |= sdl.FLIP_VERTICAL
+	}
+
+	return angle, flip
+}
+
+func (gml *GameMapLayer) GetName() string {
+	return gml.layerName
+}
+
+// GetProperties returns the custom properties of the layer (may be nil, which has no properties)
+func (gml *GameMapLayer) GetProperties() *TmxProperties {
+	return gml.properties
+}
+
+func (gm *GameMap) GetMapSize() (width, height int32) {
+	return gm.mapWidth, gm.mapHeight
+}
+
+func (gm *GameMap) GetTileSize() (width, height int32) {
+	return gm.tileWidth, gm.tileHeight
+}
+
+func (gm *GameMap) GetLayerCount() int {
+	return len(gm.layers)
+}
+
+// GetLayerIndex returns the index of the first layer named layerName, or -1 if there is no such layer
+func (gm *GameMap) GetLayerIndex(layerName string) int {
+	for index := range gm.layers {
+		if gm.layers[index].layerName == layerName {
+			return index
+		}
+	}
+	return -1
+}
+
+// GetLayer returns the layer named layerName, or nil if there is no such layer
+func (gm *GameMap) GetLayer(layerName string) *GameMapLayer {
+	if index := gm.GetLayerIndex(layerName); index >= 0 {
+		return &gm.layers[index]
+	}
+	return nil
+}
+
+// GetLayerProperties returns the custom properties of the layer (may be nil, which has no properties)
+func (gm *GameMap) GetLayerProperties(layer int) *TmxProperties {
+	gm.checkLayer(layer)
+	return gm.layers[layer].properties
+}
+
+// GetTileData returns the data the tileset defines for the tile (properties, collision shapes),
+// or nil if the tileset doesn't define anything for it
+func (gm *GameMap) GetTileData(gid uint32) *TsxTile {
+	tileId := TileIdFromGid(gid)
+	if tileId == 0 {
+		return nil
+	}
+
+	if tileSet := gm.getTilesetFromTileId(tileId); tileSet != nil {
+		return tileSet.tiles[tileId-tileSet.minTileId]
+	}
+	return nil
+}
+
+// GetTileProperties returns the custom properties the tileset defines for the tile (may be nil, which has no properties)
+func (gm *GameMap) GetTileProperties(gid uint32) *TmxProperties {
+	if tile := gm.GetTileData(gid); tile != nil {
+		return tile.Properties
+	}
+	return nil
+}
+
+// IsInside reports whether the column and row are inside the map
+func (gm *GameMap) IsInside(col, row int32) bool {
+	return col >= 0 && row >= 0 && col < gm.mapWidth && row < gm.mapHeight
+}
+
+func (gm *GameMap) checkLayer(layer int) {
+	if layer < 0 || layer >= len(gm.layers) {
+		log.Fatalf("Invalid layer index: %d. The map has %d layers", layer, len(gm.layers))
+	}
+}
+
+// GetTile returns the GID (with flip flags) at the given column and row of the layer.
+// Positions outside the map are empty (GID 0).
+func (gm *GameMap) GetTile(layer int, col, row int32) uint32 {
+	gm.checkLayer(layer)
+
+	if !gm.IsInside(col, row) {
+		return 0
+	}
+
+	return gm.layers[layer].tiles[row*gm.mapWidth+col]
+}
+
+// SetTile replaces the tile at the given column and row of the layer.
+// The gid may contain flip flags (see TileGid). Use 0 to clear the tile.
+// Positions outside the map are ignored.
+func (gm *GameMap) SetTile(layer int, col, row int32, gid uint32) {
+	gm.checkLayer(layer)
+	gm.checkGid(gid)
+
+	gm.setTile(layer, col, row, gid)
+}
+
+// FillLayer sets every tile of the layer to gid
+func (gm *GameMap) FillLayer(layer int, gid uint32) {
+	gm.FillRect(layer, 0, 0, gm.mapWidth, gm.mapHeight, gid)
+}
+
+// FillRect sets every tile inside the rectangle (in tiles) to gid.
+// The rectangle is clipped to the map bounds.
+func (gm *GameMap) FillRect(layer int, col, row, width, height int32, gid uint32) {
+	gm.checkLayer(layer)
+	gm.checkGid(gid)
+
+	for y := row; y < row+height; y++ {
+		for x := col; x < col+width; x++ {
+			gm.setTile(layer, x, y, gid)
+		}
+	}
+}
+
+// GetRect returns the GIDs inside the rectangle (in tiles), row by row.
+// Positions outside the map are returned as 0.
+func (gm *GameMap) GetRect(layer int, col, row, width, height int32) []uint32 {
+	gm.checkLayer(layer)
+
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	gids := make([]uint32, 0, width*height)
+	for y := row; y < row+height; y++ {
+		for x := col; x < col+width; x++ {
+			gids = append(gids, gm.GetTile(layer, x, y))
+		}
+	}
+
+	return gids
+}
+
+// SetRect copies the GIDs (row by row, as returned by GetRect) into the rectangle (in tiles).
+// The rectangle is clipped to the map bounds.
+func (gm *GameMap) SetRect(layer int, col, row, width, height int32, gids []uint32) {
+	gm.checkLayer(layer)
+
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	if int32(len(gids)) != width*height {
+		log.Fatalf("Invalid tile count for a %dx%d rectangle: %d", width, height, len(gids))
+	}
+
+	for _, gid := range gids {
+		gm.checkGid(gid)
+	}
+
+	for y := int32(0); y < height; y++ {
+		for x := int32(0); x < width; x++ {
+			gm.setTile(layer, col+x, row+y, gids[y*width+x])
+		}
+	}
+}
+
+type tileChangeListener struct {
+	id       int
+	listener func(layer int, col, row int32, oldGid, newGid uint32)
+}
+
+// AddTileChangeListener registers a function called after each tile change.
+// Returns the id to remove it with RemoveTileChangeListener
+func (gm *GameMap) AddTileChangeListener(listener func(layer int, col, row int32, oldGid, newGid uint32)) int {
+	gm.nextListenerId++
+	gm.tileChangeListeners = append(gm.tileChangeListeners, tileChangeListener{id: gm.nextListenerId, listener: listener})
+	return gm.nextListenerId
+}
+
+// RemoveTileChangeListener stops calling the listener (e.g. when the object listening is destroyed)
+func (gm *GameMap) RemoveTileChangeListener(id int) {
+	// Cloned, so listeners can remove themselves while the listeners are called
+	gm.tileChangeListeners = slices.DeleteFunc(slices.Clone(gm.tileChangeListeners), func(listener tileChangeListener) bool {
+		return listener.id == id
+	})
+}
+
+func (gm *GameMap) checkGid(gid uint32) {
+	tileId := TileIdFromGid(gid)
+	if tileId != 0 && gm.getTilesetFromTileId(tileId) == nil {
+		log.Fatalf("Couldn't find a tileset for tile ID %d", tileId)
+	}
+}
+
+func (gm *GameMap) setTile(layer int, col, row int32, gid uint32) {
+	if !gm.IsInside(col, row) {
+		return
+	}
+
+	index := row*gm.mapWidth + col
+	oldGid := gm.layers[layer].tiles[index]
+	if oldGid == gid {
+		return
+	}
+
+	gm.layers[layer].tiles[index] = gid
+	gm.updateElevation(layer, int(index), gid)
+
+	for _, listener := range gm.tileChangeListeners {
+		listener.listener(layer, col, row, oldGid, gid)
+	}
+}
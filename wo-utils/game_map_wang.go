@@ -0,0 +1,238 @@
+package woutils
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// WangId is the terrain color of each edge and corner of a tile, clockwise from the top edge:
+// top, top right, right, bottom right, bottom, bottom left, left, top left.
+// Colors start at 1, and 0 means no terrain (or any terrain, when used as a constraint).
+type WangId [8]uint8
+
+const (
+	WANG_TOP = iota
+	WANG_TOP_RIGHT
+	WANG_RIGHT
+	WANG_BOTTOM_RIGHT
+	WANG_BOTTOM
+	WANG_BOTTOM_LEFT
+	WANG_LEFT
+	WANG_TOP_LEFT
+)
+
+// Position of each edge and corner inside its tile, in half tiles (so positions shared by neighbor tiles are equal)
+var wangPositionOffsets = [8][2]int32{{1, 0}, {2, 0}, {2, 1}, {2, 2}, {1, 2}, {0, 2}, {0, 1}, {0, 0}}
+
+// ParseWangId parses the wangid attribute of a wang tile: 8 comma separated colors, or
+// the hexadecimal format of Tiled 1.4 (one color per digit, the top edge in the lowest digit)
+func ParseWangId(value string) (WangId, error) {
+	var wangId WangId
+
+	if hexValue, found := strings.CutPrefix(value, "0x"); found {
+		parsed, err := strconv.ParseUint(hexValue, 16, 32)
+		if err != nil {
+			return wangId, err
+		}
+		for index := range wangId {
+			wangId[index] = uint8(parsed >> (4 * index) & 0xF)
+		}
+		return wangId, nil
+	}
+
+	colors := strings.Split(value, ",")
+	if len(colors) != len(wangId) {
+		return wangId, strconv.ErrSyntax
+	}
+	for index, color := range colors {
+		parsed, err := strconv.ParseUint(strings.TrimSpace(color), 10, 8)
+		if err != nil {
+			return wangId, err
+		}
+		wangId[index] = uint8(parsed)
+	}
+	return wangId, nil
+}
+
+type wangTile struct {
+	gid    uint32
+	wangId WangId
+}
+
+// GameMapWangSet is a wang set (terrain set) of one of the tilesets of the map, used to paint terrain at runtime
+type GameMapWangSet struct {
+	name      string
+	wangType  string
+	colors    []TsxWangColor
+	tiles     []wangTile
+	positions []int // Edges and corners used by the type of the set
+	wangIds   map[uint32]WangId
+}
+
+func newGameMapWangSet(tsxWangSet *TsxWangSet, firstGid int32) *GameMapWangSet {
+	wangSet := &GameMapWangSet{
+		name:     tsxWangSet.Name,
+		wangType: tsxWangSet.Type,
+		colors:   tsxWangSet.Colors,
+		tiles:    make([]wangTile, 0, len(tsxWangSet.Tiles)),
+		wangIds:  make(map[uint32]WangId, len(tsxWangSet.Tiles)),
+	}
+
+	switch tsxWangSet.Type {
+	case "corner":
+		wangSet.positions = []int{WANG_TOP_RIGHT, WANG_BOTTOM_RIGHT, WANG_BOTTOM_LEFT, WANG_TOP_LEFT}
+	case "edge":
+		wangSet.positions = []int{WANG_TOP, WANG_RIGHT, WANG_BOTTOM, WANG_LEFT}
+	default:
+		wangSet.positions = []int{WANG_TOP, WANG_TOP_RIGHT, WANG_RIGHT, WANG_BOTTOM_RIGHT, WANG_BOTTOM, WANG_BOTTOM_LEFT, WANG_LEFT, WANG_TOP_LEFT}
+	}
+
+	for _, tsxWangTile := range tsxWangSet.Tiles {
+		wangId, err := ParseWangId(tsxWangTile.WangId)
+		if err != nil {
+			log.Printf("Invalid wangid %q of tile %d in wang set %s: %v\n", tsxWangTile.WangId, tsxWangTile.TileId, tsxWangSet.Name, err)
+			continue
+		}
+
+		gid := uint32(firstGid) + uint32(tsxWangTile.TileId)
+		wangSet.tiles = append(wangSet.tiles, wangTile{gid: gid, wangId: wangId})
+		wangSet.wangIds[gid] = wangId
+	}
+
+	return wangSet
+}
+
+func (ws *GameMapWangSet) GetName() string {
+	return ws.name
+}
+
+// GetType returns "corner", "edge" or "mixed"
+func (ws *GameMapWangSet) GetType() string {
+	return ws.wangType
+}
+
+func (ws *GameMapWangSet) GetColors() []TsxWangColor {
+	return ws.colors
+}
+
+// GetColor returns the color (starting at 1) of the terrain named name, or 0 if there is no such terrain
+func (ws *GameMapWangSet) GetColor(name string) uint8 {
+	for index := range ws.colors {
+		if ws.colors[index].Name == name {
+			return uint8(index + 1)
+		}
+	}
+	return 0
+}
+
+// GetWangId returns the terrains of the tile, or false if the tile is not part of the set
+func (ws *GameMapWangSet) GetWangId(gid uint32) (WangId, bool) {
+	wangId, ok := ws.wangIds[gid&^TILE_FLIP_FLAGS]
+	return wangId, ok
+}
+
+// findTile returns the tile that best matches the constraints. The required colors
+// weight more than the preferred ones, and 0 accepts any color.
+// Equally good tiles are chosen by the position, so the variations are spread over the map.
+func (ws *GameMapWangSet) findTile(required, preferred WangId, col, row int32) (uint32, bool) {
+	var best []uint32
+	bestScore := -1
+
+	for _, tile := range ws.tiles {
+		score := 0
+		for _, position := range ws.positions {
+			if required[position] != 0 && tile.wangId[position] != required[position] {
+				score += len(ws.positions) + 1
+			} else if preferred[position] != 0 && tile.wangId[position] != preferred[position] {
+				score++
+			}
+		}
+
+		if bestScore < 0 || score < bestScore {
+			best, bestScore = append(best[:0], tile.gid), score
+		} else if score == bestScore {
+			best = append(best, tile.gid)
+		}
+	}
+
+	if len(best) == 0 {
+		return 0, false
+	}
+
+	hash := uint32(col)*73856093 ^ uint32(row)*19349663
+	return best[hash%uint32(len(best))], true
+}
+
+// GetWangSet returns the wang set named name, of any tileset, or nil if there is no such set
+func (gm *GameMap) GetWangSet(name string) *GameMapWangSet {
+	for _, wangSet := range gm.wangSets {
+		if wangSet.name == name {
+			return wangSet
+		}
+	}
+	return nil
+}
+
+func (gm *GameMap) GetWangSets() []*GameMapWangSet {
+	return gm.wangSets
+}
+
+// PaintTerrain places terrain color (see GameMapWangSet.GetColor) at the tile, and replaces the
+// tiles around it with the transitions that match it, like the Tiled terrain brush
+func (gm *GameMap) PaintTerrain(layer int, wangSet *GameMapWangSet, color uint8, col, row int32) {
+	gm.checkLayer(layer)
+	if !gm.IsInside(col, row) {
+		return
+	}
+
+	var painted WangId
+	for _, position := range wangSet.positions {
+		painted[position] = color
+	}
+
+	gid, ok := wangSet.findTile(painted, WangId{}, col, row)
+	if !ok {
+		return
+	}
+	gm.setTile(layer, col, row, gid)
+
+	// The neighbors keep their terrains, except where they touch the painted tile
+	for neighborRow := row - 1; neighborRow <= row+1; neighborRow++ {
+		for neighborCol := col - 1; neighborCol <= col+1; neighborCol++ {
+			if (neighborCol == col && neighborRow == row) || !gm.IsInside(neighborCol, neighborRow) {
+				continue
+			}
+
+			preferred, _ := wangSet.GetWangId(gm.GetTile(layer, neighborCol, neighborRow))
+			required := sharedWangColors(painted, col-neighborCol, row-neighborRow)
+
+			if neighborGid, ok := wangSet.findTile(required, preferred, neighborCol, neighborRow); ok {
+				gm.setTile(layer, neighborCol, neighborRow, neighborGid)
+			}
+		}
+	}
+}
+
+// PaintTerrainRect paints the terrain over a rectangle of tiles (see PaintTerrain)
+func (gm *GameMap) PaintTerrainRect(layer int, wangSet *GameMapWangSet, color uint8, col, row, width, height int32) {
+	for rectRow := row; rectRow < row+height; rectRow++ {
+		for rectCol := col; rectCol < col+width; rectCol++ {
+			gm.PaintTerrain(layer, wangSet, color, rectCol, rectRow)
+		}
+	}
+}
+
+// sharedWangColors returns the colors of wangId (of a tile offset by deltaCol and deltaRow)
+// at the edges and corners a tile shares with it, or 0 at the other positions
+func sharedWangColors(wangId WangId, deltaCol, deltaRow int32) WangId {
+	var shared WangId
+	for position, offset := range wangPositionOffsets {
+		for otherPosition, otherOffset := range wangPositionOffsets {
+			if offset[0] == otherOffset[0]+2*deltaCol && offset[1] == otherOffset[1]+2*deltaRow {
+				shared[position] = wangId[otherPosition]
+			}
+		}
+	}
+	return shared
+}
@@ -1,23 +1,98 @@
 package woutils
 
 import (
+	"fmt"
+	"io/fs"
 	"log"
 
 	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
+type BlendMode uint8
+
+const (
+	BlendAlpha    BlendMode = iota // Draws over what is behind, using the transparency of the image
+	BlendAdd                       // Adds the colors (e.g. glows, fire, damage flashes)
+	BlendMultiply                  // Multiplies the colors, darkening what is behind (e.g. shadows). Ignores the transparency on renderers without custom blend modes
+	BlendNone                      // Ignores the transparency of the image
+)
+
+func (bm BlendMode) sdlBlendMode() sdl.BlendMode {
+	switch bm {
+	case BlendAdd:
+		return sdl.BLENDMODE_ADD
+	case BlendMultiply:
+		// The same as SDL_BLENDMODE_MUL: color = src * dst + dst * (1 - srcAlpha), alpha = dst
+		return sdl.ComposeCustomBlendMode(
+			sdl.BlendFactor(sdl.BLENDFACTOR_DST_COLOR), sdl.BlendFactor(sdl.BLENDFACTOR_ONE_MINUS_SRC_ALPHA), sdl.BlendOperation(sdl.BLENDOPERATION_ADD),
+			sdl.BlendFactor(sdl.BLENDFACTOR_ZERO), sdl.BlendFactor(sdl.BLENDFACTOR_ONE), sdl.BlendOperation(sdl.BLENDOPERATION_ADD),
+		)
+	case BlendNone:
+		return sdl.BLENDMODE_NONE
+	default:
+		return sdl.BLENDMODE_BLEND
+	}
+}
+
+// setTextureBlendMode sets the blend mode of the texture. Renderers without custom blend modes
+// (e.g. the software renderer) multiply with SDL_BLENDMODE_MOD instead
+func setTextureBlendMode(texture *sdl.Texture, blendMode BlendMode) {
+	if err := texture.SetBlendMode(blendMode.sdlBlendMode()); err != nil && blendMode == BlendMultiply {
+		texture.SetBlendMode(sdl.BLENDMODE_MOD)
+	}
+}
+
+// setDrawBlendMode sets the blend mode of the renderer drawing, like setTextureBlendMode
+func setDrawBlendMode(renderer *sdl.Renderer, blendMode BlendMode) {
+	if err := renderer.SetDrawBlendMode(blendMode.sdlBlendMode()); err != nil && blendMode == BlendMultiply {
+		renderer.SetDrawBlendMode(sdl.BLENDMODE_MOD)
+	}
+}
+
+// ParseBlendMode returns the blend mode with the name used in data files: "alpha", "add", "multiply" or "none"
+func ParseBlendMode(name string) (BlendMode, error) {
+	switch name {
+	case "alpha", "":
+		return BlendAlpha, nil
+	case "add":
+		return BlendAdd, nil
+	case "multiply":
+		return BlendMultiply, nil
+	case "none":
+		return BlendNone, nil
+	default:
+		return BlendAlpha, fmt.Errorf("unknown blend mode %q", name)
+	}
+}
+
+// Image draws a texture (shared by the asset manager) with its own transform and colors.
+// The color (ColorMixin) tints the image and its alpha makes it transparent: white and 255 draw it unchanged
 type Image struct {
 	womixins.HideMixin
 	womixins.RectMixin
+	womixins.ColorMixin
 	texture       *sdl.Texture
 	srcRect       sdl.Rect
 	customSrcRect bool
 	imagePath     string
+	assets        *AssetManager
+	angle         float64 // Degrees, clockwise
+	pivotX        float32 // Point the image rotates and scales around, relative to its size (0.5 is the center)
+	pivotY        float32
+	scaleX        float32
+	scaleY        float32
+	flip          sdl.RendererFlip
+	blendMode     BlendMode
 }
 
 func NewImage(context *GameContext, imagePath string) Image {
-	texture, err := LoadTexture(context.GetRenderer(), imagePath)
+	return NewImageFromFs(context, osFS, imagePath)
+}
+
+// NewImageFromFs creates an image from a file of fsys
+func NewImageFromFs(context *GameContext, fsys fs.FS, imagePath string) Image {
+	texture, err := context.GetAssets().AcquireTextureFromFs(fsys, imagePath)
 	if err != nil {
 		log.Fatalf("Failed to load image (%s) and convert to texture: %s", imagePath, err)
 	}
@@ -30,6 +105,7 @@ func NewImage(context *GameContext, imagePath string) Image {
 	return Image{
 		imagePath: imagePath,
 		texture:   texture,
+		assets:    context.GetAssets(),
 		RectMixin: womixins.RectMixin{
 			X: 0,
 			Y: 0,
@@ -39,6 +115,14 @@ func NewImage(context *GameContext, imagePath string) Image {
 		srcRect:       sdl.Rect{},
 		customSrcRect: false,
 		HideMixin:     womixins.NewHideMixin(),
+		ColorMixin:    womixins.NewColorMixin(255, 255, 255, 255),
+		angle:         0,
+		pivotX:        0.5,
+		pivotY:        0.5,
+		scaleX:        1,
+		scaleY:        1,
+		flip:          sdl.FLIP_NONE,
+		blendMode:     BlendAlpha,
 	}
 }
 
@@ -54,20 +138,125 @@ func (i *Image) SetSrcRect(x, y, w, h int32) {
 
 func (i *Image) Destroy() {
 	if i.texture != nil {
-		i.texture.Destroy()
+		i.assets.ReleaseTexture(i.texture)
+		i.texture = nil
 	}
 }
 
+// SetRotation rotates the image clockwise around its pivot
+func (i *Image) SetRotation(degrees float64) {
+	i.angle = degrees
+}
+
+func (i *Image) GetRotation() float64 {
+	return i.angle
+}
+
+// SetPivot sets the point the image rotates and scales around, relative to its size:
+// (0, 0) is the top left corner, (0.5, 0.5) the center (the default) and (1, 1) the bottom right corner
+func (i *Image) SetPivot(x, y float32) {
+	i.pivotX, i.pivotY = x, y
+}
+
+func (i *Image) GetPivot() (float32, float32) {
+	return i.pivotX, i.pivotY
+}
+
+// SetScale scales the image around its pivot, without changing its rect. 1 is the size of the rect
+func (i *Image) SetScale(scaleX, scaleY float32) {
+	i.scaleX, i.scaleY = max(scaleX, 0), max(scaleY, 0)
+}
+
+func (i *Image) GetScale() (float32, float32) {
+	return i.scaleX, i.scaleY
+}
+
+// SetFlip mirrors the image (sdl.FLIP_HORIZONTAL and/or sdl.FLIP_VERTICAL)
+func (i *Image) SetFlip(flip sdl.RendererFlip) {
+	i.flip = flip
+}
+
+func (i *Image) GetFlip() sdl.RendererFlip {
+	return i.flip
+}
+
+func (i *Image) SetBlendMode(blendMode BlendMode) {
+	i.blendMode = blendMode
+}
+
+func (i *Image) GetBlendMode() BlendMode {
+	return i.blendMode
+}
+
+// getDestRect returns the rect the image is drawn in, scaled around the pivot
+func (i *Image) getDestRect() sdl.FRect {
+	width, height := float32(i.W)*i.scaleX, float32(i.H)*i.scaleY
+	pivotX := float32(i.X) + float32(i.W)*i.pivotX
+	pivotY := float32(i.Y) + float32(i.H)*i.pivotY
+
+	return sdl.FRect{X: pivotX - width*i.pivotX, Y: pivotY - height*i.pivotY, W: width, H: height}
+}
+
 func (i *Image) Render(context *GameContext) {
-	if i.texture == nil || !i.HasArea() {
+	if i.texture == nil || !i.HasArea() || i.A == 0 {
 		return
 	}
 
+	var srcRect *sdl.Rect
 	if i.customSrcRect {
-		context.GetRenderer().Copy(i.texture, &i.srcRect, i.SdlRect())
+		srcRect = &i.srcRect
+	}
+
+	restore := applyTextureMods(i.texture, i.SdlColor(), i.blendMode)
+	defer restore()
+
+	destRect := i.getDestRect()
+	center := sdl.FPoint{X: destRect.W * i.pivotX, Y: destRect.H * i.pivotY}
+	context.GetRenderer().CopyExF(i.texture, srcRect, &destRect, i.angle, &center, i.flip)
+}
+
+// applyTextureMods sets the color, alpha and blend mode a texture is drawn with. Textures are shared, so the
+// returned function must be called after drawing, to restore the previous color, alpha and blend mode of the texture
+func applyTextureMods(texture *sdl.Texture, color sdl.Color, blendMode BlendMode) (restore func()) {
+	isTinted := color.R != 255 || color.G != 255 || color.B != 255
+	if !isTinted && color.A == 255 && blendMode == BlendAlpha {
+		return func() {}
+	}
+
+	previousColor := getTextureColorMod(texture)
+	previousAlpha, _ := texture.GetAlphaMod()
+	previousBlendMode, _ := texture.GetBlendMode()
+
+	setTextureColorMod(texture, color.R, color.G, color.B)
+	texture.SetAlphaMod(color.A)
+	setTextureBlendMode(texture, blendMode)
+
+	return func() {
+		setTextureColorMod(texture, previousColor.R, previousColor.G, previousColor.B)
+		texture.SetAlphaMod(previousAlpha)
+		texture.SetBlendMode(previousBlendMode)
+	}
+}
+
+// textureColorMods keeps the color mods set with setTextureColorMod, which go-sdl2 can't read back.
+// Only the textures not white are kept. Used in the main thread only
+var textureColorMods = map[*sdl.Texture]sdl.Color{}
+
+func setTextureColorMod(texture *sdl.Texture, r, g, b uint8) {
+	texture.SetColorMod(r, g, b)
+	if r == 255 && g == 255 && b == 255 {
+		delete(textureColorMods, texture)
 	} else {
-		context.GetRenderer().Copy(i.texture, nil, i.SdlRect())
+		textureColorMods[texture] = sdl.Color{R: r, G: g, B: b, A: 255}
+	}
+}
+
+// getTextureColorMod returns the color mod of the texture, white (the SDL default) if it was never changed
+func getTextureColorMod(texture *sdl.Texture) sdl.Color {
+	if color, exists := textureColorMods[texture]; exists {
+		return color
 	}
+	return sdl.Color{R: 255, G: 255, B: 255, A: 255}
 }
 
 func (i *Image) FollowCursor(x, y int32) bool {
@@ -1,6 +1,8 @@
 package woutils
 
 import (
+	"io/fs"
+
 	"github.com/veandco/go-sdl2/img"
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -9,8 +11,13 @@ import (
 // It takes a renderer (to which the texture will be bound) and the filename of the image.
 // Returns a pointer to the created SDL texture and an error if any occurs during loading or texture creation.
 func LoadTexture(renderer *sdl.Renderer, filename string) (*sdl.Texture, error) {
-	// Load the image using SDL_image
-	surface, err := img.Load(filename)
+	return LoadTextureFromFs(renderer, osFS, filename)
+}
+
+// LoadTextureFromFs loads an image from a file of fsys
+// and converts it into an SDL texture.
+func LoadTextureFromFs(renderer *sdl.Renderer, fsys fs.FS, filename string) (*sdl.Texture, error) {
+	surface, err := LoadSurfaceFromFs(fsys, filename)
 	if err != nil {
 		return nil, err
 	}
@@ -25,6 +32,23 @@ func LoadTexture(renderer *sdl.Renderer, filename string) (*sdl.Texture, error)
 	return texture, nil
 }
 
+// LoadSurfaceFromFs loads an image from a file of fsys into an SDL surface, which must be freed by the caller.
+func LoadSurfaceFromFs(fsys fs.FS, filename string) (*sdl.Surface, error) {
+	data, err := fs.ReadFile(fsys, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	rwops, err := sdl.RWFromMem(data)
+	if err != nil {
+		return nil, err
+	}
+	defer rwops.Close()
+
+	// Load the image using SDL_image
+	return img.LoadRW(rwops, false)
+}
+
 // LoadTextureFromEmbedFs loads a PNG image from embedded filesystem data and converts it into an SDL texture.
 // It accepts a renderer (to which the texture will be bound) and the image data as a byte slice.
 // Returns a pointer to the created SDL texture. It does NOT return an error because the data is already in memory
@@ -0,0 +1,160 @@
+package woutils
+
+import (
+	"io/fs"
+	"log"
+	"slices"
+)
+
+// MapBuilder creates a GameMap in code (e.g. procedurally generated levels), from tilesets and tile layers.
+// The built map renders and saves (see GameMap.Save) like a map loaded from a TMX file.
+type MapBuilder struct {
+	tmxMap TmxMap
+	fsys   fs.FS // Where the tilesets and their images are read from
+}
+
+// NewMapBuilder starts an isometric map with mapWidth x mapHeight tiles of tileWidth x tileHeight pixels
+func NewMapBuilder(mapWidth, mapHeight, tileWidth, tileHeight int32) MapBuilder {
+	return MapBuilder{
+		tmxMap: TmxMap{
+			Version:      "1.10",
+			TiledVersion: "1.10.2",
+			Orientation:  "isometric",
+			RenderOrder:  "right-down",
+			Width:        int(mapWidth),
+			Height:       int(mapHeight),
+			TileWidth:    int(tileWidth),
+			TileHeight:   int(tileHeight),
+			Infinite:     0,
+			NextLayerId:  1,
+			NextObjectId: 1,
+		},
+		fsys: osFS,
+	}
+}
+
+// SetFs reads the tilesets and their images from fsys
+// instead of the operating system files. Call it before AddTileSet
+func (mb *MapBuilder) SetFs(fsys fs.FS) {
+	mb.fsys = fsys
+}
+
+// AddTileSet adds the tileset of a TSX file. Returns the GID of its first tile,
+// the GID of any other tile is the first GID plus the tile ID in the tileset
+func (mb *MapBuilder) AddTileSet(tsxPath string) uint32 {
+	var tsxTileSet TsxTileSet
+	if err := ReadXmlFromFs(mb.fsys, tsxPath, &tsxTileSet); err != nil {
+		log.Fatalln(err)
+	}
+
+	firstGid := 1
+	if count := len(mb.tmxMap.TileSets); count > 0 {
+		last := mb.tmxMap.TileSets[count-1]
+		firstGid = last.FirstGid + last.TsxData.TileCount
+	}
+
+	mb.tmxMap.TileSets = append(mb.tmxMap.TileSets, TmxTileSetRef{
+		FirstGid: firstGid,
+		Source:   tsxPath,
+		TsxPath:  tsxPath,
+		TsxData:  &tsxTileSet,
+	})
+
+	return uint32(firstGid)
+}
+
+// AddLayer adds an empty tile layer over the previous ones. Returns the index of the layer
+func (mb *MapBuilder) AddLayer(name string) int {
+	mb.tmxMap.Layers = append(mb.tmxMap.Layers, TmxLayer{
+		Id:     mb.tmxMap.NextLayerId,
+		Name:   name,
+		Width:  mb.tmxMap.Width,
+		Height: mb.tmxMap.Height,
+		Data: TmxLayerData{
+			Encoding: "csv",
+			Tiles:    make([]uint32, mb.tmxMap.Width*mb.tmxMap.Height),
+		},
+	})
+	mb.tmxMap.NextLayerId++
+
+	return len(mb.tmxMap.Layers) - 1
+}
+
+func (mb *MapBuilder) checkLayer(layer int) {
+	if layer < 0 || layer >= len(mb.tmxMap.Layers) {
+		log.Fatalf("Layer index %d out of range, the map has %d layers", layer, len(mb.tmxMap.Layers))
+	}
+}
+
+func (mb *MapBuilder) SetTile(layer int, col, row int32, gid uint32) {
+	mb.checkLayer(layer)
+	if col < 0 || row < 0 || col >= int32(mb.tmxMap.Width) || row >= int32(mb.tmxMap.Height) {
+		return
+	}
+	mb.tmxMap.Layers[layer].Data.Tiles[int(row)*mb.tmxMap.Width+int(col)] = gid
+}
+
+// SetLayerTiles replaces all the tiles of the layer, row by row
+func (mb *MapBuilder) SetLayerTiles(layer int, gids []uint32) {
+	mb.checkLayer(layer)
+	if len(gids) != mb.tmxMap.Width*mb.tmxMap.Height {
+		log.Fatalf("Layer needs %d tiles, got %d", mb.tmxMap.Width*mb.tmxMap.Height, len(gids))
+	}
+	copy(mb.tmxMap.Layers[layer].Data.Tiles, gids)
+}
+
+// SetProperty sets a custom property of the map (e.g. "heightstep")
+func (mb *MapBuilder) SetProperty(name string, value string) {
+	if mb.tmxMap.Properties == nil {
+		mb.tmxMap.Properties = &TmxProperties{}
+	}
+	mb.tmxMap.Properties.Set(name, value)
+}
+
+// SetLayerProperty sets a custom property of the layer (e.g. "walkable" or "height")
+func (mb *MapBuilder) SetLayerProperty(layer int, name string, value string) {
+	mb.checkLayer(layer)
+	if mb.tmxMap.Layers[layer].Properties == nil {
+		mb.tmxMap.Layers[layer].Properties = &TmxProperties{}
+	}
+	mb.tmxMap.Layers[layer].Properties.Set(name, value)
+}
+
+// AddObject adds an object (spawn points, triggers...) to the object group named groupName,
+// creating the group if needed. The object gets a new ID, which is returned
+func (mb *MapBuilder) AddObject(groupName string, object TmxObject) int {
+	objectGroup := mb.tmxMap.GetObjectGroup(groupName)
+	if objectGroup == nil {
+		mb.tmxMap.ObjectGroups = append(mb.tmxMap.ObjectGroups, TmxObjectGroup{
+			Id:   mb.tmxMap.NextLayerId,
+			Name: groupName,
+		})
+		mb.tmxMap.NextLayerId++
+		objectGroup = &mb.tmxMap.ObjectGroups[len(mb.tmxMap.ObjectGroups)-1]
+	}
+
+	object.Id = mb.tmxMap.NextObjectId
+	mb.tmxMap.NextObjectId++
+	objectGroup.Objects = append(objectGroup.Objects, object)
+
+	return object.Id
+}
+
+// Build creates the GameMap, loading the tileset textures. The builder can keep
+// being used to build other maps, changes to it don't affect the built maps
+func (mb *MapBuilder) Build(context *GameContext) GameMap {
+	tmxMap := mb.tmxMap
+	tmxMap.TileSets = slices.Clone(mb.tmxMap.TileSets)
+	tmxMap.Layers = slices.Clone(mb.tmxMap.Layers)
+	tmxMap.ObjectGroups = slices.Clone(mb.tmxMap.ObjectGroups)
+	tmxMap.Properties = mb.tmxMap.Properties.clone()
+	for index := range tmxMap.Layers {
+		tmxMap.Layers[index].Data.Tiles = slices.Clone(tmxMap.Layers[index].Data.Tiles)
+		tmxMap.Layers[index].Properties = tmxMap.Layers[index].Properties.clone()
+	}
+	for index := range tmxMap.ObjectGroups {
+		tmxMap.ObjectGroups[index].Objects = slices.Clone(tmxMap.ObjectGroups[index].Objects)
+	}
+
+	return newGameMapFromTiledMap(context, TiledMap{path: "", fsys: mb.fsys, TmxMap: tmxMap})
+}
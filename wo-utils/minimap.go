@@ -0,0 +1,352 @@
+package woutils
+
+import (
+	"log"
+	"slices"
+	"unsafe"
+
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// MinimapMarker is a point shown over the minimap (a unit, a quest goal...).
+// Markers of entities follow the entity base position
+type MinimapMarker struct {
+	X      int32 // Position in map coordinates
+	Y      int32
+	Color  sdl.Color
+	Size   int32 // Side of the marker square, in pixels of the screen
+	entity MapEntity
+}
+
+// Minimap draws a scaled down GameMap, with one pixel of a texture for each tile, colored with the
+// property "minimapcolor" of the topmost tile (or of its layer), or the average color of the tile image.
+// It shows the area of the map the camera sees, and clicking (or dragging) on it moves the camera.
+type Minimap struct {
+	womixins.HideMixin
+	context         *GameContext
+	gameMap         *GameMap
+	listenerId      int // Of the tile change listener, removed by Destroy
+	destRect        womixins.RectMixin
+	texture         *sdl.Texture
+	pixels          []byte // RGBA of each tile, row by row
+	dirtyRect       sdl.Rect
+	isDirty         bool
+	tileColors      map[uint32]sdl.Color
+	tileSetImages   map[*GameMapTileSet]*sdl.Surface // Kept to compute the color of the tiles placed later
+	markers         []*MinimapMarker
+	viewportColor   sdl.Color
+	isDragging      bool
+	canListenEvents bool
+}
+
+func NewMinimap(context *GameContext, gameMap *GameMap, width, height int32) *Minimap {
+	texture, err := context.GetRenderer().CreateTexture(uint32(sdl.PIXELFORMAT_RGBA32), sdl.TEXTUREACCESS_STREAMING, gameMap.mapWidth, gameMap.mapHeight)
+	if err != nil {
+		log.Fatalf("Failed to create minimap texture: %s", err)
+	}
+	texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+
+	minimap := &Minimap{
+		HideMixin:       womixins.NewHideMixin(),
+		context:         context,
+		gameMap:         gameMap,
+		destRect:        womixins.NewRectMixin(0, 0, width, height),
+		texture:         texture,
+		pixels:          make([]byte, gameMap.mapWidth*gameMap.mapHeight*4),
+		tileColors:      make(map[uint32]sdl.Color),
+		tileSetImages:   make(map[*GameMapTileSet]*sdl.Surface),
+		markers:         nil,
+		viewportColor:   sdl.Color{R: 255, G: 255, B: 255, A: 255},
+		canListenEvents: true,
+	}
+
+	for row := int32(0); row < gameMap.mapHeight; row++ {
+		for col := int32(0); col < gameMap.mapWidth; col++ {
+			minimap.updateTile(col, row)
+		}
+	}
+
+	// Only the changed tiles are recolored and uploaded to the texture
+	minimap.listenerId = gameMap.AddTileChangeListener(func(layer int, col, row int32, oldGid, newGid uint32) {
+		minimap.updateTile(col, row)
+	})
+
+	return minimap
+}
+
+func (m *Minimap) updateTile(col, row int32) {
+	color := sdl.Color{}
+	for layer := len(m.gameMap.layers) - 1; layer >= 0; layer-- {
+		gid := m.gameMap.GetTile(layer, col, row)
+		if gid == 0 {
+			continue
+		}
+
+		layerColor := m.gameMap.layers[layer].properties.GetColor("minimapcolor", sdl.Color{})
+		color = m.gameMap.GetTileProperties(gid).GetColor("minimapcolor", layerColor)
+		if color.A == 0 {
+			color = m.getTileImageColor(gid)
+		}
+		if color.A != 0 {
+			break
+		}
+	}
+
+	index := (row*m.gameMap.mapWidth + col) * 4
+	m.pixels[index], m.pixels[index+1], m.pixels[index+2], m.pixels[index+3] = color.R, color.G, color.B, color.A
+
+	tileRect := sdl.Rect{X: col, Y: row, W: 1, H: 1}
+	if m.isDirty {
+		m.dirtyRect = m.dirtyRect.Union(&tileRect)
+	} else {
+		m.dirtyRect, m.isDirty = tileRect, true
+	}
+}
+
+// getTileImageColor returns the average color of the visible pixels of the tile image
+func (m *Minimap) getTileImageColor(gid uint32) sdl.Color {
+	tileId := TileIdFromGid(gid)
+	if color, ok := m.tileColors[uint32(tileId)]; ok {
+		return color
+	}
+
+	color := sdl.Color{}
+	tileSet := m.gameMap.getTilesetFromTileId(tileId)
+	if surface := m.getTileSetImage(tileSet); surface != nil {
+		tileRect := tileSet.getTileRect(tileId)
+		pixels := surface.Pixels()
+
+		var red, green, blue, alpha uint64
+		for y := max(tileRect.Y, 0); y < min(tileRect.Y+tileRect.H, surface.H); y++ {
+			for x := max(tileRect.X, 0); x < min(tileRect.X+tileRect.W, surface.W); x++ {
+				index := int(y*surface.Pitch + x*4)
+				pixelAlpha := uint64(pixels[index+3])
+				red += uint64(pixels[index]) * pixelAlpha
+				green += uint64(pixels[index+1]) * pixelAlpha
+				blue += uint64(pixels[index+2]) * pixelAlpha
+				alpha += pixelAlpha
+			}
+		}
+
+		if alpha > 0 {
+			color = sdl.Color{R: uint8(red / alpha), G: uint8(green / alpha), B: uint8(blue / alpha), A: 255}
+		}
+	}
+
+	m.tileColors[uint32(tileId)] = color
+	return color
+}
+
+func (m *Minimap) getTileSetImage(tileSet *GameMapTileSet) *sdl.Surface {
+	if tileSet == nil {
+		return nil
+	}
+
+	if surface, ok := m.tileSetImages[tileSet]; ok {
+		return surface
+	}
+
+	var converted *sdl.Surface
+	if surface, err := LoadSurfaceFromFs(tileSet.fsys, tileSet.textureSourcePath); err != nil {
+		log.Printf("Failed to load %s for the minimap colors: %s\n", tileSet.textureSourcePath, err)
+	} else {
+		converted, err = surface.ConvertFormat(uint32(sdl.PIXELFORMAT_RGBA32), 0)
+		surface.Free()
+		if err != nil {
+			log.Printf("Failed to convert %s for the minimap colors: %s\n", tileSet.textureSourcePath, err)
+		}
+	}
+
+	m.tileSetImages[tileSet] = converted
+	return converted
+}
+
+func (m *Minimap) SetPosition(x, y int32) {
+	m.destRect.SetPosition(x, y)
+}
+
+func (m *Minimap) SetSize(width, height int32) {
+	m.destRect.SetSize(width, height)
+}
+
+func (m *Minimap) SetViewportColor(color sdl.Color) {
+	m.viewportColor = color
+}
+
+// AddMarker shows a point of the map (in map coordinates) over the minimap
+func (m *Minimap) AddMarker(x, y int32, color sdl.Color) *MinimapMarker {
+	marker := &MinimapMarker{X: x, Y: y, Color: color, Size: 3}
+	m.markers = append(m.markers, marker)
+	return marker
+}
+
+// AddEntityMarker shows the entity over the minimap, following it
+func (m *Minimap) AddEntityMarker(entity MapEntity, color sdl.Color) *MinimapMarker {
+	x, y := entity.GetBasePosition()
+	marker := m.AddMarker(x, y, color)
+	marker.entity = entity
+	return marker
+}
+
+func (m *Minimap) RemoveMarker(marker *MinimapMarker) {
+	m.markers = slices.DeleteFunc(m.markers, func(other *MinimapMarker) bool {
+		return other == marker
+	})
+}
+
+// getScale returns the size of a map pixel in the minimap, and where the map starts (its leftmost
+// point is the left corner of the bottom left tile), keeping the proportions of the map
+func (m *Minimap) getScale() (scale float32, offsetX float32, offsetY float32) {
+	mapWidth := float32((m.gameMap.mapWidth + m.gameMap.mapHeight) * m.gameMap.tileWidth / 2)
+	mapHeight := float32((m.gameMap.mapWidth + m.gameMap.mapHeight) * m.gameMap.tileHeight / 2)
+	scale = min(float32(m.destRect.W)/mapWidth, float32(m.destRect.H)/mapHeight)
+
+	left := float32(-(m.gameMap.mapHeight - 1) * m.gameMap.tileWidth / 2)
+	offsetX = float32(m.destRect.X) + (float32(m.destRect.W)-mapWidth*scale)/2 - left*scale
+	offsetY = float32(m.destRect.Y) + (float32(m.destRect.H)-mapHeight*scale)/2
+	return scale, offsetX, offsetY
+}
+
+// WorldToMinimap converts a point in map coordinates to the screen position in the minimap
+func (m *Minimap) WorldToMinimap(x, y int32) (float32, float32) {
+	scale, offsetX, offsetY := m.getScale()
+	return offsetX + float32(x)*scale, offsetY + float32(y)*scale
+}
+
+// MinimapToWorld converts a point of the screen in the minimap to map coordinates
+func (m *Minimap) MinimapToWorld(x, y int32) (int32, int32) {
+	scale, offsetX, offsetY := m.getScale()
+	return int32((float32(x) - offsetX) / scale), int32((float32(y) - offsetY) / scale)
+}
+
+func (m *Minimap) Render(gc *GameContext) {
+	if !m.destRect.HasArea() {
+		return
+	}
+
+	renderer := gc.GetRenderer()
+	if m.isDirty {
+		offset := (m.dirtyRect.Y*m.gameMap.mapWidth + m.dirtyRect.X) * 4
+		if err := m.texture.Update(&m.dirtyRect, unsafe.Pointer(&m.pixels[offset]), int(m.gameMap.mapWidth*4)); err != nil {
+			log.Printf("Failed to update minimap texture: %s\n", err)
+		}
+		m.isDirty = false
+	}
+
+	// The map is a diamond: the corners of the tile grid are the corners of the texture
+	corner := func(col, row int32, textureX, textureY float32) sdl.Vertex {
+		x, y := m.WorldToMinimap((col-row)*m.gameMap.tileWidth/2+m.gameMap.tileWidth/2, (col+row)*m.gameMap.tileHeight/2)
+		return sdl.Vertex{
+			Position: sdl.FPoint{X: x, Y: y},
+			Color:    sdl.Color{R: 255, G: 255, B: 255, A: 255},
+			TexCoord: sdl.FPoint{X: textureX, Y: textureY},
+		}
+	}
+	vertices := []sdl.Vertex{
+		corner(0, 0, 0, 0),
+		corner(m.gameMap.mapWidth, 0, 1, 0),
+		corner(m.gameMap.mapWidth, m.gameMap.mapHeight, 1, 1),
+		corner(0, m.gameMap.mapHeight, 0, 1),
+	}
+	renderer.RenderGeometry(m.texture, vertices, []int32{0, 1, 2, 0, 2, 3})
+
+	red, green, blue, alpha, _ := renderer.GetDrawColor()
+	defer renderer.SetDrawColor(red, green, blue, alpha)
+	renderer.SetClipRect(m.destRect.SdlRect())
+	defer renderer.SetClipRect(nil)
+
+	// Area the camera sees
+	windowWidth, windowHeight := gc.GetWindowSize()
+	viewX, viewY := gc.Camera.ScreenToWorld(0, 0)
+	viewRight, viewBottom := gc.Camera.ScreenToWorld(windowWidth, windowHeight)
+	left, top := m.WorldToMinimap(viewX, viewY)
+	right, bottom := m.WorldToMinimap(viewRight, viewBottom)
+	renderer.SetDrawColor(m.viewportColor.R, m.viewportColor.G, m.viewportColor.B, m.viewportColor.A)
+	renderer.DrawRectF(&sdl.FRect{X: left, Y: top, W: right - left, H: bottom - top})
+
+	for _, marker := range m.markers {
+		if marker.entity != nil {
+			marker.X, marker.Y = marker.entity.GetBasePosition()
+		}
+
+		x, y := m.WorldToMinimap(marker.X, marker.Y)
+		size := float32(marker.Size)
+		renderer.SetDrawColor(marker.Color.R, marker.Color.G, marker.Color.B, marker.Color.A)
+		renderer.FillRectF(&sdl.FRect{X: x - size/2, Y: y - size/2, W: size, H: size})
+	}
+}
+
+// isOverMap reports whether the point of the screen is over the map drawn in the minimap
+func (m *Minimap) isOverMap(x, y int32) bool {
+	if !m.destRect.Contains(x, y) {
+		return false
+	}
+
+	col, row := m.gameMap.WorldToTile(m.MinimapToWorld(x, y))
+	return m.gameMap.IsInside(col, row)
+}
+
+func (m *Minimap) moveCamera(x, y int32) {
+	worldX, worldY := m.MinimapToWorld(x, y)
+	windowWidth, windowHeight := m.context.GetWindowSize()
+	m.context.Camera.CenterOn(worldX, worldY, windowWidth, windowHeight)
+}
+
+func (m *Minimap) DisableEvents() {
+	m.canListenEvents = false
+	m.isDragging = false
+}
+
+func (m *Minimap) EnableEvents() {
+	m.canListenEvents = true
+}
+
+func (m *Minimap) MouseMovementListener(x, y int32) bool {
+	if !m.canListenEvents || m.IsHidden() || !m.isDragging {
+		return false
+	}
+
+	m.moveCamera(x, y)
+	return true
+}
+
+func (m *Minimap) MouseClickListener(x, y int32, button uint8, isPressed bool) bool {
+	if !m.canListenEvents || m.IsHidden() || button != sdl.BUTTON_LEFT {
+		return false
+	}
+
+	if isPressed && m.isOverMap(x, y) {
+		m.isDragging = true
+		m.moveCamera(x, y)
+		return true
+	}
+
+	if !isPressed && m.isDragging {
+		m.isDragging = false
+		return true
+	}
+
+	return false
+}
+
+func (m *Minimap) AddListeners(context *GameContext) {
+	context.AddMouseMovementListener(m.MouseMovementListener)
+	context.AddMouseClickListener(m.MouseClickListener)
+}
+
+func (m *Minimap) Destroy() {
+	m.gameMap.RemoveTileChangeListener(m.listenerId)
+
+	if m.texture != nil {
+		m.texture.Destroy()
+		m.texture = nil
+	}
+
+	for _, surface := range m.tileSetImages {
+		if surface != nil {
+			surface.Free()
+		}
+	}
+	m.tileSetImages = nil
+}
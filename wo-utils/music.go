@@ -1,30 +1,50 @@
 package woutils
 
 import (
+	"io/fs"
 	"log"
 
 	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
 )
 
 type Music struct {
 	path    string
 	file    *mix.Music
 	canStop bool
+	rwops   *sdl.RWops // Keeps the data of the music open while it is played
+	data    []byte
 }
 
 // Use this for background tracks that will be paused or stopped frequently.
 // This structure does not allow multiple instances of the same music to be played at the same time.
 func NewMusic(path string) Music {
-	var err error
-	var music *mix.Music
+	return NewMusicFromFs(osFS, path)
+}
+
+// NewMusicFromFs creates a music from a file of fsys.
+// The file is kept in memory until the music is destroyed
+func NewMusicFromFs(fsys fs.FS, path string) Music {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	if music, err = mix.LoadMUS(path); err != nil {
+	rwops, err := sdl.RWFromMem(data)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	music, err := mix.LoadMUSRW(rwops, 0)
+	if err != nil {
 		log.Fatalln(err)
 	}
 
 	return Music{
-		path: path,
-		file: music,
+		path:  path,
+		file:  music,
+		rwops: rwops,
+		data:  data,
 	}
 }
 
@@ -54,6 +74,11 @@ func (a *Music) Stop() {
 func (a *Music) Destroy() {
 	if a.file != nil {
 		a.file.Free()
+		a.file = nil
 	}
 
+	if a.rwops != nil {
+		a.rwops.Close()
+		a.rwops = nil
+	}
 }
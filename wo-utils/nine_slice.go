@@ -0,0 +1,134 @@
+package woutils
+
+import (
+	"io/fs"
+	"log"
+
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// NineSlice draws an image scaled to any size keeping its borders crisp: the image is split by its insets
+// in nine parts, the corners keep their size, the edges stretch along one axis and the center along both.
+// Used for panels, dialogs and button skins (see Button.SetSkin).
+type NineSlice struct {
+	womixins.HideMixin
+	womixins.RectMixin
+	womixins.ColorMixin
+	texture     *sdl.Texture // Shared by the asset manager
+	assets      *AssetManager
+	srcRect     sdl.Rect // Area of the texture with the nine parts
+	left        int32    // Insets, in pixels of the image
+	top         int32
+	right       int32
+	bottom      int32
+	borderScale float32 // Size of the borders on the screen, relative to the image
+	blendMode   BlendMode
+}
+
+// NewNineSlice creates a nine-slice from an image, split by the insets (the sizes of its borders)
+func NewNineSlice(context *GameContext, imagePath string, left, top, right, bottom int32) NineSlice {
+	return NewNineSliceFromFs(context, osFS, imagePath, left, top, right, bottom)
+}
+
+// NewNineSliceFromFs creates a nine-slice from an image of fsys
+func NewNineSliceFromFs(context *GameContext, fsys fs.FS, imagePath string, left, top, right, bottom int32) NineSlice {
+	texture, err := context.GetAssets().AcquireTextureFromFs(fsys, imagePath)
+	if err != nil {
+		log.Fatalf("Failed to load nine-slice image (%s) and convert to texture: %s", imagePath, err)
+	}
+
+	_, _, width, height, err := texture.Query()
+	if err != nil {
+		log.Fatalf("Failed to get texture information (%s): %s", imagePath, err)
+	}
+
+	return NineSlice{
+		HideMixin:   womixins.NewHideMixin(),
+		RectMixin:   womixins.NewRectMixin(0, 0, width, height),
+		ColorMixin:  womixins.NewColorMixin(255, 255, 255, 255),
+		texture:     texture,
+		assets:      context.GetAssets(),
+		srcRect:     sdl.Rect{X: 0, Y: 0, W: width, H: height},
+		left:        left,
+		top:         top,
+		right:       right,
+		bottom:      bottom,
+		borderScale: 1,
+		blendMode:   BlendAlpha,
+	}
+}
+
+// SetSrcRect uses only an area of the image (e.g. a panel of a sheet of UI elements). The insets are inside the area
+func (ns *NineSlice) SetSrcRect(x, y, w, h int32) {
+	ns.srcRect = sdl.Rect{X: x, Y: y, W: w, H: h}
+}
+
+func (ns *NineSlice) SetInsets(left, top, right, bottom int32) {
+	ns.left, ns.top, ns.right, ns.bottom = left, top, right, bottom
+}
+
+func (ns *NineSlice) GetInsets() (left, top, right, bottom int32) {
+	return ns.left, ns.top, ns.right, ns.bottom
+}
+
+// SetBorderScale scales the borders on the screen (e.g. 0.25 for an image drawn for 4K screens)
+func (ns *NineSlice) SetBorderScale(borderScale float32) {
+	ns.borderScale = max(borderScale, 0)
+}
+
+func (ns *NineSlice) SetBlendMode(blendMode BlendMode) {
+	ns.blendMode = blendMode
+}
+
+// Clone returns a copy of the nine-slice sharing its texture. Both must be destroyed
+func (ns *NineSlice) Clone() NineSlice {
+	clone := *ns
+	clone.HideMixin = womixins.NewHideMixin()
+	if clone.texture != nil {
+		ns.assets.RetainTexture(clone.texture)
+	}
+	return clone
+}
+
+func (ns *NineSlice) Destroy() {
+	if ns.texture != nil {
+		ns.assets.ReleaseTexture(ns.texture)
+		ns.texture = nil
+	}
+}
+
+// sliceEdges returns the edges of the three columns (or rows) of a length split by two insets.
+// If the length is smaller than the insets, the insets shrink proportionally
+func sliceEdges(start, length, startInset, endInset int32) [4]int32 {
+	if startInset+endInset > length && startInset+endInset > 0 {
+		startInset = int32(int64(startInset) * int64(length) / int64(startInset+endInset))
+		endInset = length - startInset
+	}
+	return [4]int32{start, start + startInset, start + length - endInset, start + length}
+}
+
+func (ns *NineSlice) Render(context *GameContext) {
+	if ns.texture == nil || !ns.HasArea() || ns.A == 0 {
+		return
+	}
+
+	restore := applyTextureMods(ns.texture, ns.SdlColor(), ns.blendMode)
+	defer restore()
+
+	srcColumns := sliceEdges(ns.srcRect.X, ns.srcRect.W, ns.left, ns.right)
+	srcRows := sliceEdges(ns.srcRect.Y, ns.srcRect.H, ns.top, ns.bottom)
+	destColumns := sliceEdges(ns.X, ns.W, int32(float32(ns.left)*ns.borderScale), int32(float32(ns.right)*ns.borderScale))
+	destRows := sliceEdges(ns.Y, ns.H, int32(float32(ns.top)*ns.borderScale), int32(float32(ns.bottom)*ns.borderScale))
+
+	renderer := context.GetRenderer()
+	for row := range 3 {
+		for column := range 3 {
+			srcRect := sdl.Rect{X: srcColumns[column], Y: srcRows[row], W: srcColumns[column+1] - srcColumns[column], H: srcRows[row+1] - srcRows[row]}
+			destRect := sdl.Rect{X: destColumns[column], Y: destRows[row], W: destColumns[column+1] - destColumns[column], H: destRows[row+1] - destRows[row]}
+			if srcRect.W > 0 && srcRect.H > 0 && destRect.W > 0 && destRect.H > 0 {
+				renderer.Copy(ns.texture, &srcRect, &destRect)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package woutils
+
+import (
+	"math/rand/v2"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// ParticleRange is a value picked at random between Min and Max for each particle
+type ParticleRange struct {
+	Min float32 `json:"min"`
+	Max float32 `json:"max"`
+}
+
+func (pr ParticleRange) random(rng *rand.Rand) float32 {
+	return pr.Min + (pr.Max-pr.Min)*rng.Float32()
+}
+
+type ParticleVector struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// ParticleKey is a value at a time of the life of the particles, from 0 (born) to 1 (dead)
+type ParticleKey struct {
+	Time  float32 `json:"time"`
+	Value float32 `json:"value"`
+}
+
+// ParticleColorKey is a color at a time of the life of the particles, from 0 (born) to 1 (dead)
+type ParticleColorKey struct {
+	Time float32 `json:"time"`
+	R    uint8   `json:"r"`
+	G    uint8   `json:"g"`
+	B    uint8   `json:"b"`
+}
+
+// ParticleBurst emits Count particles at once, Time seconds after the emitter starts.
+// If Interval is greater than 0, the burst repeats every Interval seconds
+type ParticleBurst struct {
+	Time     float32 `json:"time"`
+	Count    int     `json:"count"`
+	Interval float32 `json:"interval"`
+}
+
+// ParticleEmitterConfig describes the particles of an emitter. It can be loaded from a JSON file
+// (see LoadParticleEmitter), where the texture path is relative to the JSON file:
+//
+//	{
+//	  "texture": "smoke.png", "blendMode": "alpha", "maxParticles": 200,
+//	  "rate": 30, "lifetime": {"min": 1, "max": 2},
+//	  "speed": {"min": 20, "max": 40}, "angle": {"min": 250, "max": 290},
+//	  "gravity": {"x": 0, "y": -10}, "drag": 0.5,
+//	  "size": [{"time": 0, "value": 8}, {"time": 1, "value": 32}],
+//	  "alpha": [{"time": 0, "value": 200}, {"time": 1, "value": 0}]
+//	}
+type ParticleEmitterConfig struct {
+	Texture      string             `json:"texture"`      // Image of the particles. Without it, particles are squares
+	SrcRect      *sdl.Rect          `json:"srcRect"`      // Area of the image (e.g. a sprite of a sheet), as {"x", "y", "w", "h"}
+	BlendMode    string             `json:"blendMode"`    // "alpha", "add", "multiply" or "none" (see ParseBlendMode)
+	MaxParticles int                `json:"maxParticles"` // Size of the pool. No particles are emitted while all are alive
+	Duration     float32            `json:"duration"`     // Seconds emitting. 0 emits until Stop
+	Rate         float32            `json:"rate"`         // Particles emitted per second
+	Bursts       []ParticleBurst    `json:"bursts"`
+	SpawnRadius  ParticleRange      `json:"spawnRadius"` // Distance from the emitter where particles are born
+	Lifetime     ParticleRange      `json:"lifetime"`    // Seconds
+	Speed        ParticleRange      `json:"speed"`       // Pixels per second
+	Angle        ParticleRange      `json:"angle"`       // Direction of the movement, in degrees clockwise from the right
+	Rotation     ParticleRange      `json:"rotation"`    // Initial rotation, in degrees
+	Spin         ParticleRange      `json:"spin"`        // Rotation speed, in degrees per second
+	Gravity      ParticleVector     `json:"gravity"`     // Acceleration, in pixels per second squared
+	Drag         float32            `json:"drag"`        // Fraction of the velocity lost each second
+	Colors       []ParticleColorKey `json:"colors"`      // Color over the lifetime. White if empty
+	Alpha        []ParticleKey      `json:"alpha"`       // Alpha over the lifetime. 255 if empty
+	Size         []ParticleKey      `json:"size"`        // Size in pixels over the lifetime. The texture size if empty
+}
+
+// interpolateKeys returns the value of the keys at time t, linearly interpolated
+func interpolateKeys(keys []ParticleKey, t float32, defaultValue float32) float32 {
+	if len(keys) == 0 {
+		return defaultValue
+	}
+	if t <= keys[0].Time {
+		return keys[0].Value
+	}
+	for i := 1; i < len(keys); i++ {
+		if t <= keys[i].Time {
+			previous, next := keys[i-1], keys[i]
+			if next.Time <= previous.Time {
+				return next.Value
+			}
+			progress := (t - previous.Time) / (next.Time - previous.Time)
+			return previous.Value + (next.Value-previous.Value)*progress
+		}
+	}
+	return keys[len(keys)-1].Value
+}
+
+// interpolateColorKeys returns the color of the keys at time t, linearly interpolated
+func interpolateColorKeys(keys []ParticleColorKey, t float32) (r, g, b uint8) {
+	if len(keys) == 0 {
+		return 255, 255, 255
+	}
+	if t <= keys[0].Time {
+		return keys[0].R, keys[0].G, keys[0].B
+	}
+	for i := 1; i < len(keys); i++ {
+		if t <= keys[i].Time {
+			previous, next := keys[i-1], keys[i]
+			if next.Time <= previous.Time {
+				return next.R, next.G, next.B
+			}
+			progress := (t - previous.Time) / (next.Time - previous.Time)
+			mix := func(from, to uint8) uint8 {
+				return uint8(float32(from) + (float32(to)-float32(from))*progress)
+			}
+			return mix(previous.R, next.R), mix(previous.G, next.G), mix(previous.B, next.B)
+		}
+	}
+	last := keys[len(keys)-1]
+	return last.R, last.G, last.B
+}
@@ -0,0 +1,377 @@
+package woutils
+
+import (
+	"io/fs"
+	"log"
+	"math"
+	"math/rand/v2"
+
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type particle struct {
+	x, y                 float32
+	velocityX, velocityY float32
+	rotation             float32 // Degrees
+	spin                 float32
+	age                  float32 // Seconds
+	lifetime             float32
+}
+
+// ParticleAnchor is something an emitter can follow (e.g. a MapEntity)
+type ParticleAnchor interface {
+	GetBasePosition() (x, y int32)
+}
+
+// ParticleEmitter emits particles (e.g. spells, smoke, dust trails and weather), described by a
+// ParticleEmitterConfig. The particles are kept in a pool, and drawn at once, so nothing is allocated each frame.
+//
+// By default the particles are in WorldSpace, moved and zoomed with the camera, and the emitter advances
+// with the real time between renders (see SetAutoUpdate). The emitter is a MapEntity, so it can also
+// be added to a GameMap to be drawn between its tiles.
+type ParticleEmitter struct {
+	womixins.HideMixin
+	config        ParticleEmitterConfig
+	blendMode     BlendMode
+	texture       *sdl.Texture // Shared by the asset manager. Nil draws squares
+	textureWidth  float32
+	textureHeight float32
+	srcRect       sdl.Rect
+	assets        *AssetManager
+	space         DrawSpace
+	x             float32
+	y             float32
+	anchor        ParticleAnchor
+	anchorOffsetX float32
+	anchorOffsetY float32
+	particles     []particle // Pool. The alive particles are the first aliveCount
+	aliveCount    int
+	vertices      []sdl.Vertex
+	indices       []int32
+	elapsed       float32   // Seconds since Start
+	emitDebt      float32   // Particles the rate owes, emitted when they add up to one
+	nextBursts    []float32 // Time of the next run of each burst, -1 when done
+	isEmitting    bool
+	autoUpdate    bool
+	lastTicks     uint64
+	rng           *rand.Rand
+}
+
+// NewParticleEmitter creates an emitter from a config. The texture path of the config is a path of the OS
+func NewParticleEmitter(context *GameContext, config ParticleEmitterConfig) ParticleEmitter {
+	return newParticleEmitter(context, osFS, config.Texture, config)
+}
+
+// LoadParticleEmitter creates an emitter from a JSON file (see ParticleEmitterConfig)
+func LoadParticleEmitter(context *GameContext, jsonPath string) ParticleEmitter {
+	return LoadParticleEmitterFromFs(context, osFS, jsonPath)
+}
+
+// LoadParticleEmitterFromFs creates an emitter from a JSON file of fsys (see ParticleEmitterConfig)
+func LoadParticleEmitterFromFs(context *GameContext, fsys fs.FS, jsonPath string) ParticleEmitter {
+	var config ParticleEmitterConfig
+	if err := ReadJsonFromFs(fsys, jsonPath, &config); err != nil {
+		log.Fatalf("Failed to read particle emitter (%s): %s", jsonPath, err)
+	}
+
+	texturePath := ""
+	if config.Texture != "" {
+		texturePath = ResolvePath(jsonPath, config.Texture)
+	}
+	return newParticleEmitter(context, fsys, texturePath, config)
+}
+
+func newParticleEmitter(context *GameContext, fsys fs.FS, texturePath string, config ParticleEmitterConfig) ParticleEmitter {
+	blendMode, err := ParseBlendMode(config.BlendMode)
+	if err != nil {
+		log.Fatalf("Invalid particle emitter: %s", err)
+	}
+
+	if config.MaxParticles <= 0 {
+		config.MaxParticles = DEFAULT_MAX_PARTICLES
+	}
+
+	pe := ParticleEmitter{
+		HideMixin:  womixins.NewHideMixin(),
+		config:     config,
+		blendMode:  blendMode,
+		assets:     context.GetAssets(),
+		space:      WorldSpace,
+		particles:  make([]particle, config.MaxParticles),
+		vertices:   make([]sdl.Vertex, config.MaxParticles*4),
+		indices:    make([]int32, config.MaxParticles*6),
+		nextBursts: make([]float32, len(config.Bursts)),
+		autoUpdate: true,
+		rng:        rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+
+	if texturePath != "" {
+		pe.texture, err = pe.assets.AcquireTextureFromFs(fsys, texturePath)
+		if err != nil {
+			log.Fatalf("Failed to load particle image (%s) and convert to texture: %s", texturePath, err)
+		}
+
+		_, _, width, height, err := pe.texture.Query()
+		if err != nil {
+			log.Fatalf("Failed to get texture information (%s): %s", texturePath, err)
+		}
+		pe.textureWidth, pe.textureHeight = float32(width), float32(height)
+		pe.srcRect = sdl.Rect{X: 0, Y: 0, W: width, H: height}
+		if config.SrcRect != nil {
+			pe.srcRect = *config.SrcRect
+		}
+	}
+
+	// Two triangles by particle, they never change
+	for i := range config.MaxParticles {
+		first := int32(i * 4)
+		copy(pe.indices[i*6:], []int32{first, first + 1, first + 2, first, first + 2, first + 3})
+	}
+
+	pe.Start()
+	return pe
+}
+
+// SetSpace sets if the emitter and its particles are in the world (moved by the camera) or on the screen
+func (pe *ParticleEmitter) SetSpace(space DrawSpace) {
+	pe.space = space
+}
+
+// SetPosition moves the emitter. The particles already emitted stay where they are (e.g. a dust trail)
+func (pe *ParticleEmitter) SetPosition(x, y float32) {
+	pe.x, pe.y = x, y
+}
+
+func (pe *ParticleEmitter) GetPosition() (x, y float32) {
+	return pe.x, pe.y
+}
+
+// GetBasePosition returns the position of the emitter, used to sort it between the entities of a GameMap
+func (pe *ParticleEmitter) GetBasePosition() (x, y int32) {
+	return int32(pe.x), int32(pe.y)
+}
+
+// AttachTo makes the emitter follow the anchor (e.g. a unit), at an offset from its position
+func (pe *ParticleEmitter) AttachTo(anchor ParticleAnchor, offsetX, offsetY float32) {
+	pe.anchor = anchor
+	pe.anchorOffsetX, pe.anchorOffsetY = offsetX, offsetY
+	pe.followAnchor()
+}
+
+// Detach stops following the anchor, keeping the current position
+func (pe *ParticleEmitter) Detach() {
+	pe.anchor = nil
+}
+
+func (pe *ParticleEmitter) followAnchor() {
+	if pe.anchor != nil {
+		x, y := pe.anchor.GetBasePosition()
+		pe.x, pe.y = float32(x)+pe.anchorOffsetX, float32(y)+pe.anchorOffsetY
+	}
+}
+
+// SetSeed makes the particles the same on each run (e.g. for replays)
+func (pe *ParticleEmitter) SetSeed(seed uint64) {
+	pe.rng = rand.New(rand.NewPCG(seed, seed))
+}
+
+// SetAutoUpdate makes the emitter advance with the real time between renders (true by default),
+// up to MAX_PARTICLE_STEP_MS per render. Without it, call Update
+func (pe *ParticleEmitter) SetAutoUpdate(autoUpdate bool) {
+	pe.autoUpdate = autoUpdate
+	pe.lastTicks = 0
+}
+
+// Start (re)starts emitting, from the beginning of the duration and the bursts. Emitters start when created
+func (pe *ParticleEmitter) Start() {
+	pe.isEmitting = true
+	pe.elapsed = 0
+	pe.emitDebt = 0
+	for i, burst := range pe.config.Bursts {
+		pe.nextBursts[i] = burst.Time
+	}
+}
+
+// Stop stops emitting. The particles alive keep moving until they die
+func (pe *ParticleEmitter) Stop() {
+	pe.isEmitting = false
+}
+
+// Clear removes all the particles
+func (pe *ParticleEmitter) Clear() {
+	pe.aliveCount = 0
+}
+
+func (pe *ParticleEmitter) IsEmitting() bool {
+	return pe.isEmitting
+}
+
+// IsAlive reports whether the emitter is emitting or has particles alive (e.g. to remove finished effects)
+func (pe *ParticleEmitter) IsAlive() bool {
+	return pe.isEmitting || pe.aliveCount > 0
+}
+
+func (pe *ParticleEmitter) GetParticleCount() int {
+	return pe.aliveCount
+}
+
+// Emit emits count particles at once, besides the rate and the bursts (e.g. an impact)
+func (pe *ParticleEmitter) Emit(count int) {
+	for range count {
+		if pe.aliveCount == len(pe.particles) {
+			return
+		}
+
+		config := &pe.config
+		spawnAngle := 2 * math.Pi * pe.rng.Float64()
+		spawnDistance := config.SpawnRadius.random(pe.rng)
+		angle := float64(config.Angle.random(pe.rng)) * math.Pi / 180
+		speed := config.Speed.random(pe.rng)
+
+		pe.particles[pe.aliveCount] = particle{
+			x:         pe.x + spawnDistance*float32(math.Cos(spawnAngle)),
+			y:         pe.y + spawnDistance*float32(math.Sin(spawnAngle)),
+			velocityX: speed * float32(math.Cos(angle)),
+			velocityY: speed * float32(math.Sin(angle)),
+			rotation:  config.Rotation.random(pe.rng),
+			spin:      config.Spin.random(pe.rng),
+			age:       0,
+			lifetime:  config.Lifetime.random(pe.rng),
+		}
+		pe.aliveCount++
+	}
+}
+
+// Update advances the emitter and its particles by deltaMs milliseconds
+func (pe *ParticleEmitter) Update(deltaMs uint64) {
+	deltaTime := float32(deltaMs) / 1000
+	pe.followAnchor()
+
+	if pe.isEmitting {
+		pe.emit(deltaTime)
+	}
+
+	config := &pe.config
+	dragFactor := max(1-config.Drag*deltaTime, 0)
+	for i := 0; i < pe.aliveCount; {
+		p := &pe.particles[i]
+		p.age += deltaTime
+		if p.age >= p.lifetime {
+			// The last alive particle takes its place
+			pe.aliveCount--
+			pe.particles[i] = pe.particles[pe.aliveCount]
+			continue
+		}
+
+		p.velocityX = (p.velocityX + config.Gravity.X*deltaTime) * dragFactor
+		p.velocityY = (p.velocityY + config.Gravity.Y*deltaTime) * dragFactor
+		p.x += p.velocityX * deltaTime
+		p.y += p.velocityY * deltaTime
+		p.rotation += p.spin * deltaTime
+		i++
+	}
+}
+
+// emit emits the particles of the rate and the bursts due in the time elapsed
+func (pe *ParticleEmitter) emit(deltaTime float32) {
+	config := &pe.config
+	if config.Duration > 0 {
+		deltaTime = min(deltaTime, config.Duration-pe.elapsed)
+	}
+	pe.elapsed += deltaTime
+
+	pe.emitDebt += config.Rate * deltaTime
+	count := int(pe.emitDebt)
+	pe.emitDebt -= float32(count)
+	pe.Emit(count)
+
+	for i, burst := range config.Bursts {
+		for pe.nextBursts[i] >= 0 && pe.elapsed >= pe.nextBursts[i] {
+			pe.Emit(burst.Count)
+			if burst.Interval > 0 {
+				pe.nextBursts[i] += burst.Interval
+			} else {
+				pe.nextBursts[i] = -1
+			}
+		}
+	}
+
+	if config.Duration > 0 && pe.elapsed >= config.Duration {
+		pe.isEmitting = false
+	}
+}
+
+func (pe *ParticleEmitter) Render(context *GameContext) {
+	if pe.autoUpdate {
+		ticks := sdl.GetTicks64()
+		if pe.lastTicks != 0 {
+			pe.Update(min(ticks-pe.lastTicks, MAX_PARTICLE_STEP_MS))
+		}
+		pe.lastTicks = ticks
+	}
+
+	if pe.aliveCount == 0 {
+		return
+	}
+
+	config := &pe.config
+	offsetX, offsetY, factorX, factorY := spaceTransform(context, pe.space)
+
+	// Texture coordinates and proportions of the particles, the same for all
+	var left, top, right, bottom float32
+	defaultSize, aspectRatio := DEFAULT_PARTICLE_SIZE, float32(1)
+	if pe.texture != nil {
+		left, top = float32(pe.srcRect.X)/pe.textureWidth, float32(pe.srcRect.Y)/pe.textureHeight
+		right, bottom = float32(pe.srcRect.X+pe.srcRect.W)/pe.textureWidth, float32(pe.srcRect.Y+pe.srcRect.H)/pe.textureHeight
+		defaultSize, aspectRatio = float32(pe.srcRect.W), float32(pe.srcRect.H)/float32(max(pe.srcRect.W, 1))
+	}
+
+	for i := range pe.aliveCount {
+		p := &pe.particles[i]
+		t := p.age / max(p.lifetime, 0.001)
+
+		r, g, b := interpolateColorKeys(config.Colors, t)
+		alpha := uint8(min(max(interpolateKeys(config.Alpha, t, 255), 0), 255))
+		color := sdl.Color{R: r, G: g, B: b, A: alpha}
+
+		size := interpolateKeys(config.Size, t, defaultSize)
+		halfWidth, halfHeight := size/2*factorX, size*aspectRatio/2*factorY
+		centerX, centerY := (p.x+offsetX)*factorX, (p.y+offsetY)*factorY
+
+		sin, cos := math.Sincos(float64(p.rotation) * math.Pi / 180)
+		corner := func(x, y, textureX, textureY float32) sdl.Vertex {
+			return sdl.Vertex{
+				Position: sdl.FPoint{X: centerX + x*float32(cos) - y*float32(sin), Y: centerY + x*float32(sin) + y*float32(cos)},
+				Color:    color,
+				TexCoord: sdl.FPoint{X: textureX, Y: textureY},
+			}
+		}
+		pe.vertices[i*4] = corner(-halfWidth, -halfHeight, left, top)
+		pe.vertices[i*4+1] = corner(halfWidth, -halfHeight, right, top)
+		pe.vertices[i*4+2] = corner(halfWidth, halfHeight, right, bottom)
+		pe.vertices[i*4+3] = corner(-halfWidth, halfHeight, left, bottom)
+	}
+
+	renderer := context.GetRenderer()
+	if pe.texture != nil {
+		// The texture is shared, so its blend mode is restored
+		previousBlendMode, _ := pe.texture.GetBlendMode()
+		setTextureBlendMode(pe.texture, pe.blendMode)
+		defer pe.texture.SetBlendMode(previousBlendMode)
+	} else {
+		var previousBlendMode sdl.BlendMode
+		renderer.GetDrawBlendMode(&previousBlendMode)
+		setDrawBlendMode(renderer, pe.blendMode)
+		defer renderer.SetDrawBlendMode(previousBlendMode)
+	}
+
+	renderer.RenderGeometry(pe.texture, pe.vertices[:pe.aliveCount*4], pe.indices[:pe.aliveCount*6])
+}
+
+func (pe *ParticleEmitter) Destroy() {
+	if pe.texture != nil {
+		pe.assets.ReleaseTexture(pe.texture)
+		pe.texture = nil
+	}
+}
@@ -0,0 +1,228 @@
+package woutils
+
+import (
+	"log"
+	"math"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// PostEffect changes the image of a RenderTarget after its renderables are drawn (see RenderTarget.AddEffect).
+// The effects only draw textures and rectangles, so they work with the software renderer too
+type PostEffect interface {
+	// Apply changes the texture of the target, which the renderer is drawing into
+	Apply(context *GameContext, target *RenderTarget)
+	Destroy()
+}
+
+// scratchTexture is a texture effects draw into, recreated when the size needed changes
+type scratchTexture struct {
+	texture      *sdl.Texture
+	width        int32
+	height       int32
+	scaleQuality string
+}
+
+func (st *scratchTexture) get(renderer *sdl.Renderer, width, height int32) *sdl.Texture {
+	if st.texture != nil && st.width == width && st.height == height {
+		return st.texture
+	}
+
+	st.destroy()
+	texture, err := createTargetTexture(renderer, width, height, st.scaleQuality)
+	if err != nil {
+		log.Fatalf("Failed to create texture for post-processing (%dx%d): %s", width, height, err)
+	}
+	st.texture, st.width, st.height = texture, width, height
+	return texture
+}
+
+func (st *scratchTexture) destroy() {
+	if st.texture != nil {
+		st.texture.Destroy()
+		st.texture = nil
+	}
+}
+
+// copyWithoutBlending draws the whole source over the whole destination, replacing its pixels
+func copyWithoutBlending(renderer *sdl.Renderer, source, destination *sdl.Texture) {
+	previousBlendMode, _ := source.GetBlendMode()
+	source.SetBlendMode(sdl.BLENDMODE_NONE)
+	defer source.SetBlendMode(previousBlendMode)
+
+	renderer.SetRenderTarget(destination)
+	renderer.Copy(source, nil, nil)
+}
+
+// ColorGradeEffect multiplies the colors of the target by a color (e.g. gray dims the world behind
+// a pause menu, orange makes a sunset)
+type ColorGradeEffect struct {
+	color sdl.Color
+}
+
+func NewColorGradeEffect(r, g, b uint8) *ColorGradeEffect {
+	return &ColorGradeEffect{color: sdl.Color{R: r, G: g, B: b, A: 255}}
+}
+
+func (cge *ColorGradeEffect) SetColor(r, g, b uint8) {
+	cge.color = sdl.Color{R: r, G: g, B: b, A: 255}
+}
+
+func (cge *ColorGradeEffect) Apply(context *GameContext, target *RenderTarget) {
+	renderer := context.GetRenderer()
+	red, green, blue, alpha, _ := renderer.GetDrawColor()
+	var blendMode sdl.BlendMode
+	renderer.GetDrawBlendMode(&blendMode)
+
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_MOD)
+	renderer.SetDrawColor(cge.color.R, cge.color.G, cge.color.B, cge.color.A)
+	renderer.FillRect(nil)
+
+	renderer.SetDrawColor(red, green, blue, alpha)
+	renderer.SetDrawBlendMode(blendMode)
+}
+
+func (cge *ColorGradeEffect) Destroy() {}
+
+const VIGNETTE_TEXTURE_SIZE int32 = 128 // Stretched over the target, smoothly
+
+// VignetteEffect darkens the borders of the target
+type VignetteEffect struct {
+	strength float32 // Alpha of the corners, from 0 to 1
+	radius   float32 // Distance from the center where the darkening starts, from 0 to 1 (the corners)
+	texture  *sdl.Texture
+}
+
+func NewVignetteEffect(strength, radius float32) *VignetteEffect {
+	return &VignetteEffect{strength: strength, radius: radius}
+}
+
+// SetVignette changes the strength and the radius (see NewVignetteEffect)
+func (ve *VignetteEffect) SetVignette(strength, radius float32) {
+	ve.strength, ve.radius = strength, radius
+	ve.Destroy() // Created again with the new values
+}
+
+// createTexture creates the overlay: black, more opaque away from the center
+func (ve *VignetteEffect) createTexture(renderer *sdl.Renderer) {
+	size := VIGNETTE_TEXTURE_SIZE
+	pixels := make([]byte, size*size*4)
+	for y := range size {
+		for x := range size {
+			// Distance from the center, 1 on the corners
+			dx := (float64(x)+0.5)/float64(size) - 0.5
+			dy := (float64(y)+0.5)/float64(size) - 0.5
+			distance := math.Hypot(dx, dy) / math.Sqrt(0.5)
+
+			// Smoothstep from the radius to the corners
+			t := min(max((distance-float64(ve.radius))/max(1-float64(ve.radius), 0.001), 0), 1)
+			alpha := float64(ve.strength) * t * t * (3 - 2*t)
+			pixels[(y*size+x)*4+3] = uint8(min(max(alpha, 0), 1) * 255)
+		}
+	}
+
+	previousQuality := sdl.GetHint(sdl.HINT_RENDER_SCALE_QUALITY)
+	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "linear")
+	texture, err := renderer.CreateTexture(uint32(sdl.PIXELFORMAT_RGBA32), sdl.TEXTUREACCESS_STATIC, size, size)
+	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, previousQuality)
+	if err != nil {
+		log.Fatalf("Failed to create vignette texture: %s", err)
+	}
+
+	if err := texture.Update(nil, unsafe.Pointer(&pixels[0]), int(size*4)); err != nil {
+		log.Fatalf("Failed to update vignette texture: %s", err)
+	}
+	texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+	ve.texture = texture
+}
+
+func (ve *VignetteEffect) Apply(context *GameContext, target *RenderTarget) {
+	if ve.texture == nil {
+		ve.createTexture(context.GetRenderer())
+	}
+	context.GetRenderer().Copy(ve.texture, nil, nil)
+}
+
+func (ve *VignetteEffect) Destroy() {
+	if ve.texture != nil {
+		ve.texture.Destroy()
+		ve.texture = nil
+	}
+}
+
+// PixelateEffect lowers the resolution of the target, drawing it with big pixels (e.g. retro looks, teleports)
+type PixelateEffect struct {
+	pixelSize int32
+	scratch   scratchTexture
+}
+
+func NewPixelateEffect(pixelSize int32) *PixelateEffect {
+	return &PixelateEffect{
+		pixelSize: pixelSize,
+		scratch:   scratchTexture{scaleQuality: "nearest"},
+	}
+}
+
+func (pe *PixelateEffect) SetPixelSize(pixelSize int32) {
+	pe.pixelSize = pixelSize
+}
+
+func (pe *PixelateEffect) Apply(context *GameContext, target *RenderTarget) {
+	if pe.pixelSize <= 1 {
+		return
+	}
+
+	renderer := context.GetRenderer()
+	width, height := target.GetTextureSize()
+	small := pe.scratch.get(renderer, max(width/pe.pixelSize, 1), max(height/pe.pixelSize, 1))
+
+	copyWithoutBlending(renderer, target.GetTexture(), small)
+	copyWithoutBlending(renderer, small, target.GetTexture())
+}
+
+func (pe *PixelateEffect) Destroy() {
+	pe.scratch.destroy()
+}
+
+// BlurEffect blurs the target (e.g. the world behind a pause menu), halving its resolution
+// a number of times with smooth scaling, and scaling it back up
+type BlurEffect struct {
+	passes  int
+	scratch []scratchTexture
+}
+
+// NewBlurEffect creates a blur. Each pass doubles the blur (1 to 5 passes look good)
+func NewBlurEffect(passes int) *BlurEffect {
+	return &BlurEffect{passes: passes}
+}
+
+func (be *BlurEffect) SetPasses(passes int) {
+	be.passes = passes
+}
+
+func (be *BlurEffect) Apply(context *GameContext, target *RenderTarget) {
+	for len(be.scratch) < be.passes {
+		be.scratch = append(be.scratch, scratchTexture{scaleQuality: "linear"})
+	}
+
+	renderer := context.GetRenderer()
+	width, height := target.GetTextureSize()
+	source := target.GetTexture()
+	for pass := range be.passes {
+		width, height = max(width/2, 1), max(height/2, 1)
+		smaller := be.scratch[pass].get(renderer, width, height)
+		copyWithoutBlending(renderer, source, smaller)
+		source = smaller
+	}
+
+	if source != target.GetTexture() {
+		copyWithoutBlending(renderer, source, target.GetTexture())
+	}
+}
+
+func (be *BlurEffect) Destroy() {
+	for i := range be.scratch {
+		be.scratch[i].destroy()
+	}
+}
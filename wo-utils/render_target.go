@@ -0,0 +1,189 @@
+package woutils
+
+import (
+	"log"
+
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// RenderTarget is an offscreen texture: a group of renderables (e.g. the world of a scene) is drawn into it,
+// changed by post-processing effects (see PostEffect), then drawn on the screen with its own tint, alpha,
+// blend mode and size.
+//
+// Renderables added with Add are drawn into the target each render. Other things can be drawn into it
+// between Begin and End.
+type RenderTarget struct {
+	womixins.HideMixin
+	womixins.RectMixin
+	womixins.ColorMixin
+	texture        *sdl.Texture
+	width          int32
+	height         int32
+	clearColor     sdl.Color
+	blendMode      BlendMode
+	renderables    []Renderable
+	effects        []PostEffect
+	previousTarget *sdl.Texture // Restored by End, so targets can be nested
+}
+
+// NewRenderTarget creates a target of the given size, drawn over the same area of the screen.
+// Use GameContext.GetWindowSize for a target of the whole window
+func NewRenderTarget(context *GameContext, width, height int32) RenderTarget {
+	texture, err := createTargetTexture(context.GetRenderer(), width, height, "")
+	if err != nil {
+		log.Fatalf("Failed to create render target (%dx%d): %s", width, height, err)
+	}
+
+	return RenderTarget{
+		HideMixin:   womixins.NewHideMixin(),
+		RectMixin:   womixins.NewRectMixin(0, 0, width, height),
+		ColorMixin:  womixins.NewColorMixin(255, 255, 255, 255),
+		texture:     texture,
+		width:       width,
+		height:      height,
+		clearColor:  sdl.Color{R: 0, G: 0, B: 0, A: 0},
+		blendMode:   BlendAlpha,
+		renderables: nil,
+		effects:     nil,
+	}
+}
+
+// createTargetTexture creates a texture to draw into. The scale quality ("nearest" or "linear") is how
+// the texture is sampled when drawn at another size. Empty keeps the current quality of the renderer
+func createTargetTexture(renderer *sdl.Renderer, width, height int32, scaleQuality string) (*sdl.Texture, error) {
+	if scaleQuality != "" {
+		previousQuality := sdl.GetHint(sdl.HINT_RENDER_SCALE_QUALITY)
+		sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, scaleQuality)
+		defer sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, previousQuality)
+	}
+
+	texture, err := renderer.CreateTexture(uint32(sdl.PIXELFORMAT_RGBA32), sdl.TEXTUREACCESS_TARGET, width, height)
+	if err != nil {
+		return nil, err
+	}
+	texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+	return texture, nil
+}
+
+// Add adds a renderable drawn into the target each render, in the order added
+func (rt *RenderTarget) Add(renderable Renderable) {
+	rt.renderables = append(rt.renderables, renderable)
+}
+
+func (rt *RenderTarget) Remove(renderable Renderable) {
+	for i, added := range rt.renderables {
+		if added == renderable {
+			rt.renderables = append(rt.renderables[:i], rt.renderables[i+1:]...)
+			return
+		}
+	}
+}
+
+// AddEffect adds a post-processing effect, applied after the effects added before. The target destroys it
+func (rt *RenderTarget) AddEffect(effect PostEffect) {
+	rt.effects = append(rt.effects, effect)
+}
+
+// RemoveEffect removes an effect without destroying it (e.g. to turn a blur on and off). Destroy it when not needed
+func (rt *RenderTarget) RemoveEffect(effect PostEffect) {
+	for i, added := range rt.effects {
+		if added == effect {
+			rt.effects = append(rt.effects[:i], rt.effects[i+1:]...)
+			return
+		}
+	}
+}
+
+func (rt *RenderTarget) GetEffects() []PostEffect {
+	return rt.effects
+}
+
+// SetClearColor sets the color of the target before drawing (transparent by default)
+func (rt *RenderTarget) SetClearColor(r, g, b, a uint8) {
+	rt.clearColor = sdl.Color{R: r, G: g, B: b, A: a}
+}
+
+func (rt *RenderTarget) SetBlendMode(blendMode BlendMode) {
+	rt.blendMode = blendMode
+}
+
+// GetTextureSize returns the size of the offscreen texture (GetSize returns the size drawn on the screen)
+func (rt *RenderTarget) GetTextureSize() (width, height int32) {
+	return rt.width, rt.height
+}
+
+func (rt *RenderTarget) GetTexture() *sdl.Texture {
+	return rt.texture
+}
+
+// Resize recreates the texture with another size (e.g. when the window is resized), and draws it over the new size
+func (rt *RenderTarget) Resize(context *GameContext, width, height int32) {
+	texture, err := createTargetTexture(context.GetRenderer(), width, height, "")
+	if err != nil {
+		log.Fatalf("Failed to resize render target (%dx%d): %s", width, height, err)
+	}
+
+	rt.texture.Destroy()
+	rt.texture = texture
+	rt.width, rt.height = width, height
+	rt.SetSize(width, height)
+}
+
+// Begin makes the renderer draw into the target, cleared with the clear color
+func (rt *RenderTarget) Begin(context *GameContext) {
+	renderer := context.GetRenderer()
+	rt.previousTarget = renderer.GetRenderTarget()
+	if err := renderer.SetRenderTarget(rt.texture); err != nil {
+		log.Fatalf("Failed to draw into the render target: %s", err)
+	}
+
+	red, green, blue, alpha, _ := renderer.GetDrawColor()
+	renderer.SetDrawColor(rt.clearColor.R, rt.clearColor.G, rt.clearColor.B, rt.clearColor.A)
+	renderer.Clear()
+	renderer.SetDrawColor(red, green, blue, alpha)
+}
+
+// End applies the effects and makes the renderer draw where it drew before Begin
+func (rt *RenderTarget) End(context *GameContext) {
+	for _, effect := range rt.effects {
+		effect.Apply(context, rt)
+	}
+
+	context.GetRenderer().SetRenderTarget(rt.previousTarget)
+	rt.previousTarget = nil
+}
+
+// Render draws the renderables into the target (if any were added), then draws the target
+func (rt *RenderTarget) Render(context *GameContext) {
+	if len(rt.renderables) > 0 {
+		rt.Begin(context)
+		for _, renderable := range rt.renderables {
+			if renderable.IsVisible() {
+				renderable.Render(context)
+			}
+		}
+		rt.End(context)
+	}
+
+	if !rt.HasArea() || rt.A == 0 {
+		return
+	}
+
+	restore := applyTextureMods(rt.texture, rt.SdlColor(), rt.blendMode)
+	defer restore()
+	context.GetRenderer().Copy(rt.texture, nil, rt.SdlRect())
+}
+
+// Destroy destroys the texture and the effects. The renderables added are not destroyed
+func (rt *RenderTarget) Destroy() {
+	for _, effect := range rt.effects {
+		effect.Destroy()
+	}
+	rt.effects = nil
+
+	if rt.texture != nil {
+		rt.texture.Destroy()
+		rt.texture = nil
+	}
+}
@@ -0,0 +1,305 @@
+package woutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type AnimationMode uint8
+
+const (
+	AnimationLoop     AnimationMode = iota
+	AnimationPingPong               // Plays forward and backward, repeatedly
+	AnimationOnce                   // Stops at the last frame
+)
+
+type AnimationFrame struct {
+	SrcRect      sdl.Rect // Area of the sheet texture with the frame
+	OffsetX      int32    // Position of SrcRect inside the full frame, for sheets with trimmed transparent borders
+	OffsetY      int32
+	SourceWidth  int32 // Size of the full frame, before trimming
+	SourceHeight int32
+	Duration     uint32   // Milliseconds
+	Events       []string // Fired when the frame is shown (e.g. "footstep" or "hit")
+}
+
+type AnimationClip struct {
+	Name   string
+	Frames []AnimationFrame
+	Mode   AnimationMode
+}
+
+// AddFrameEvent fires event (see AnimatedSprite.OnFrameEvent) whenever the frame is shown
+func (ac *AnimationClip) AddFrameEvent(frame int, event string) {
+	if frame < 0 || frame >= len(ac.Frames) {
+		log.Fatalf("Frame %d out of range, clip %s has %d frames", frame, ac.Name, len(ac.Frames))
+	}
+	ac.Frames[frame].Events = append(ac.Frames[frame].Events, event)
+}
+
+// SpriteSheet is a texture with frames of animations, grouped in named clips.
+// A sheet can be shared by many AnimatedSprite.
+type SpriteSheet struct {
+	texture   *sdl.Texture
+	clips     map[string]*AnimationClip
+	clipNames []string // In the order the clips were added
+	assets    *AssetManager
+}
+
+// NewSpriteSheet creates a sheet without clips from an image. Add the clips with AddClip or AddGridClip
+func NewSpriteSheet(context *GameContext, imagePath string) SpriteSheet {
+	return NewSpriteSheetFromFs(context, osFS, imagePath)
+}
+
+// NewSpriteSheetFromFs creates a sheet without clips from an image of fsys
+func NewSpriteSheetFromFs(context *GameContext, fsys fs.FS, imagePath string) SpriteSheet {
+	texture, err := context.GetAssets().AcquireTextureFromFs(fsys, imagePath)
+	if err != nil {
+		log.Fatalf("Failed to load sprite sheet image (%s) and convert to texture: %s", imagePath, err)
+	}
+
+	return SpriteSheet{
+		texture:   texture,
+		clips:     make(map[string]*AnimationClip),
+		clipNames: nil,
+		assets:    context.GetAssets(),
+	}
+}
+
+// LoadSpriteSheet loads a sheet exported as JSON (hash or array) by Aseprite or TexturePacker.
+// The clips are, in order of preference:
+//   - The Aseprite tags, with their direction ("forward", "reverse", "pingpong" or "pingpong_reverse").
+//     Tags with repeat 1 play once
+//   - The "animations" of the meta data (lists of frame names, like the Phaser exporter of TexturePacker)
+//   - The frames grouped by name without the number (e.g. "walk_01.png" and "walk_02.png" make the clip "walk")
+func LoadSpriteSheet(context *GameContext, jsonPath string) SpriteSheet {
+	return LoadSpriteSheetFromFs(context, osFS, jsonPath)
+}
+
+// LoadSpriteSheetFromFs loads a sheet (see LoadSpriteSheet) from fsys. The image is read from fsys too
+func LoadSpriteSheetFromFs(context *GameContext, fsys fs.FS, jsonPath string) SpriteSheet {
+	var file spriteSheetFile
+	if err := ReadJsonFromFs(fsys, jsonPath, &file); err != nil {
+		log.Fatalf("Failed to read sprite sheet (%s): %s", jsonPath, err)
+	}
+
+	frames, err := decodeSpriteSheetFrames(file.Frames)
+	if err != nil {
+		log.Fatalf("Failed to read sprite sheet frames (%s): %s", jsonPath, err)
+	}
+
+	spriteSheet := NewSpriteSheetFromFs(context, fsys, ResolvePath(jsonPath, file.Meta.Image))
+
+	switch {
+	case len(file.Meta.FrameTags) > 0:
+		for _, tag := range file.Meta.FrameTags {
+			if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+				log.Fatalf("Tag %s of sprite sheet %s has invalid frames %d to %d", tag.Name, jsonPath, tag.From, tag.To)
+			}
+
+			clip := AnimationClip{Name: tag.Name, Mode: AnimationLoop}
+			for _, frame := range frames[tag.From : tag.To+1] {
+				clip.Frames = append(clip.Frames, frame.toAnimationFrame())
+			}
+
+			if strings.HasSuffix(tag.Direction, "reverse") {
+				slices.Reverse(clip.Frames)
+			}
+			if strings.HasPrefix(tag.Direction, "pingpong") {
+				clip.Mode = AnimationPingPong
+			} else if tag.Repeat == "1" {
+				clip.Mode = AnimationOnce
+			}
+
+			spriteSheet.AddClip(clip)
+		}
+	case len(file.Meta.Animations) > 0:
+		framesByName := make(map[string]spriteSheetFrame, len(frames))
+		for _, frame := range frames {
+			framesByName[frame.Filename] = frame
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(file.Meta.Animations)) {
+			frameNames := file.Meta.Animations[name]
+			clip := AnimationClip{Name: name, Mode: AnimationLoop}
+			for _, frameName := range frameNames {
+				frame, ok := framesByName[frameName]
+				if !ok {
+					log.Fatalf("Animation %s of sprite sheet %s has unknown frame %s", name, jsonPath, frameName)
+				}
+				clip.Frames = append(clip.Frames, frame.toAnimationFrame())
+			}
+			spriteSheet.AddClip(clip)
+		}
+	default:
+		for _, frame := range frames {
+			name := clipNameFromFrame(frame.Filename)
+			clip := spriteSheet.GetClip(name)
+			if clip == nil {
+				clip = spriteSheet.AddClip(AnimationClip{Name: name, Mode: AnimationLoop})
+			}
+			clip.Frames = append(clip.Frames, frame.toAnimationFrame())
+		}
+	}
+
+	return spriteSheet
+}
+
+// AddClip adds a clip (replacing the clip with the same name) and returns it, to add frame events
+func (ss *SpriteSheet) AddClip(clip AnimationClip) *AnimationClip {
+	if _, exists := ss.clips[clip.Name]; !exists {
+		ss.clipNames = append(ss.clipNames, clip.Name)
+	}
+	ss.clips[clip.Name] = &clip
+	return &clip
+}
+
+// AddGridClip adds a clip from a sheet of frames of the same size, numbered row by row from 0
+func (ss *SpriteSheet) AddGridClip(name string, frameWidth, frameHeight int32, firstFrame, frameCount int, frameDuration uint32, mode AnimationMode) *AnimationClip {
+	_, _, textureWidth, _, err := ss.texture.Query()
+	if err != nil {
+		log.Fatalf("Failed to get sprite sheet texture information: %s", err)
+	}
+
+	columns := max(int(textureWidth/frameWidth), 1)
+	clip := AnimationClip{Name: name, Mode: mode}
+	for frame := firstFrame; frame < firstFrame+frameCount; frame++ {
+		clip.Frames = append(clip.Frames, AnimationFrame{
+			SrcRect:      sdl.Rect{X: int32(frame%columns) * frameWidth, Y: int32(frame/columns) * frameHeight, W: frameWidth, H: frameHeight},
+			SourceWidth:  frameWidth,
+			SourceHeight: frameHeight,
+			Duration:     frameDuration,
+		})
+	}
+
+	return ss.AddClip(clip)
+}
+
+// GetClip returns the clip named name, or nil if there is no such clip
+func (ss *SpriteSheet) GetClip(name string) *AnimationClip {
+	return ss.clips[name]
+}
+
+func (ss *SpriteSheet) GetClipNames() []string {
+	return ss.clipNames
+}
+
+func (ss *SpriteSheet) GetTexture() *sdl.Texture {
+	return ss.texture
+}
+
+func (ss *SpriteSheet) Destroy() {
+	if ss.texture != nil {
+		ss.assets.ReleaseTexture(ss.texture)
+		ss.texture = nil
+	}
+}
+
+type spriteSheetRect struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+	W int32 `json:"w"`
+	H int32 `json:"h"`
+}
+
+type spriteSheetFrame struct {
+	Filename         string          `json:"filename"`
+	Frame            spriteSheetRect `json:"frame"`
+	Rotated          bool            `json:"rotated"`
+	Trimmed          bool            `json:"trimmed"`
+	SpriteSourceSize spriteSheetRect `json:"spriteSourceSize"`
+	SourceSize       spriteSheetRect `json:"sourceSize"`
+	Duration         uint32          `json:"duration"` // Only in Aseprite sheets
+}
+
+type spriteSheetFrameTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+	Repeat    string `json:"repeat"`
+}
+
+type spriteSheetFile struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image      string                `json:"image"`
+		FrameTags  []spriteSheetFrameTag `json:"frameTags"`
+		Animations map[string][]string   `json:"animations"`
+	} `json:"meta"`
+}
+
+func (ssf *spriteSheetFrame) toAnimationFrame() AnimationFrame {
+	if ssf.Rotated {
+		log.Printf("Frame %s is rotated, which is not supported. Export the sprite sheet without rotation\n", ssf.Filename)
+	}
+
+	frame := AnimationFrame{
+		SrcRect:      sdl.Rect{X: ssf.Frame.X, Y: ssf.Frame.Y, W: ssf.Frame.W, H: ssf.Frame.H},
+		SourceWidth:  ssf.Frame.W,
+		SourceHeight: ssf.Frame.H,
+		Duration:     ssf.Duration,
+	}
+
+	if ssf.Trimmed {
+		frame.OffsetX, frame.OffsetY = ssf.SpriteSourceSize.X, ssf.SpriteSourceSize.Y
+		frame.SourceWidth, frame.SourceHeight = ssf.SourceSize.W, ssf.SourceSize.H
+	}
+	if frame.Duration == 0 {
+		frame.Duration = DEFAULT_FRAME_DURATION
+	}
+
+	return frame
+}
+
+// decodeSpriteSheetFrames decodes the frames of array sheets, and of hash sheets keeping the order of the keys
+func decodeSpriteSheetFrames(data json.RawMessage) ([]spriteSheetFrame, error) {
+	var frames []spriteSheetFrame
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		err := json.Unmarshal(data, &frames)
+		return frames, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("frames must be an object or an array")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var frame spriteSheetFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return nil, err
+		}
+		frame.Filename = token.(string)
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// clipNameFromFrame removes the extension and the frame number of a frame name ("walk_01.png" is "walk")
+func clipNameFromFrame(filename string) string {
+	name := strings.TrimSuffix(filename, filepath.Ext(filename))
+	name = strings.TrimRight(name, "0123456789")
+	name = strings.TrimRight(name, " _-/.")
+	if name == "" {
+		return "default"
+	}
+	return name
+}
@@ -2,6 +2,7 @@ package woutils
 
 import (
 	"embed"
+	"io/fs"
 	"log"
 
 	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
@@ -15,99 +16,44 @@ type Text struct {
 	womixins.ColorMixin
 	text         string
 	renderedText *sdl.Texture
-	font         *ttf.Font
-	rwops        *sdl.RWops // rwops is used to keep the font data open (when using in-memory fonts)
+	font         *ttf.Font // Shared by the asset manager
+	assets       *AssetManager
 }
 
 //go:embed assets/fonts/default.ttf
 var fontData embed.FS
 
 func NewText(context *GameContext, text string) Text {
-	var err error
-	var surfaceText *sdl.Surface
-	var renderedText *sdl.Texture
-	var font *ttf.Font
-	color := womixins.NewColorMixin(255, 255, 255, 255)
-
-	fontBytes, err := fontData.ReadFile("assets/fonts/default.ttf")
-	if err != nil {
-		panic(err)
-	}
-
-	rwops, err := sdl.RWFromMem(fontBytes)
-	if err != nil {
-		panic(err)
-	}
-	// DO NOT defer rwops.Close() because it will close the font data and cause a panic when rendering text
-
-	font, err = ttf.OpenFontRW(rwops, 0, 16)
-	if err != nil {
-		panic(err)
-	}
-
-	if surfaceText, err = font.RenderUTF8Blended(text, color.SdlColor()); err != nil {
-		panic(err)
-	}
-	defer surfaceText.Free()
-
-	if renderedText, err = context.GetRenderer().CreateTextureFromSurface(surfaceText); err != nil {
-		panic(err)
-	}
-
-	_, _, width, height, err := renderedText.Query()
-
-	return Text{
-		HideMixin:    womixins.NewHideMixin(),
-		ColorMixin:   color,
-		text:         text,
-		renderedText: renderedText,
-		font:         font,
-		rwops:        rwops,
-		RectMixin: womixins.RectMixin{
-			X: 0,
-			Y: 0,
-			W: width,
-			H: height,
-		},
-	}
+	font := context.GetAssets().AcquireDefaultFont(DEFAULT_FONT_SIZE)
+	return newTextWithFont(context, font, text)
 }
 
 func NewTextWithCustomFont(context *GameContext, customFont string, text string) Text {
-	var err error
-	var surfaceText *sdl.Surface
-	var renderedText *sdl.Texture
-	var font *ttf.Font
-	color := womixins.NewColorMixin(255, 255, 255, 255)
-
-	if font, err = ttf.OpenFont(customFont, 16); err != nil {
-		panic(err)
-	}
-
-	if surfaceText, err = font.RenderUTF8Blended(text, color.SdlColor()); err != nil {
-		panic(err)
-	}
-	defer surfaceText.Free()
+	return NewTextWithCustomFontFromFs(context, osFS, customFont, text)
+}
 
-	if renderedText, err = context.GetRenderer().CreateTextureFromSurface(surfaceText); err != nil {
+// NewTextWithCustomFontFromFs creates a text with the font at customFont in fsys
+func NewTextWithCustomFontFromFs(context *GameContext, fsys fs.FS, customFont string, text string) Text {
+	font, err := context.GetAssets().AcquireFontFromFs(fsys, customFont, DEFAULT_FONT_SIZE)
+	if err != nil {
 		panic(err)
 	}
+	return newTextWithFont(context, font, text)
+}
 
-	_, _, width, height, err := renderedText.Query()
-
-	return Text{
+func newTextWithFont(context *GameContext, font *ttf.Font, text string) Text {
+	uiText := Text{
 		HideMixin:    womixins.NewHideMixin(),
-		ColorMixin:   color,
+		ColorMixin:   womixins.NewColorMixin(255, 255, 255, 255),
 		text:         text,
-		renderedText: renderedText,
+		renderedText: nil,
 		font:         font,
-		rwops:        nil, // rwops is nil because we are using a file font
-		RectMixin: womixins.RectMixin{
-			X: 0,
-			Y: 0,
-			W: width,
-			H: height,
-		},
+		assets:       context.GetAssets(),
+		RectMixin:    womixins.NewRectMixin(0, 0, 0, 0),
 	}
+	uiText.SetText(context, text)
+
+	return uiText
 }
 
 func (t *Text) SetText(context *GameContext, newText string) {
@@ -151,16 +97,14 @@ func (t *Text) Refresh(context *GameContext) {
 }
 
 func (t *Text) Destroy() {
-	if t.rwops != nil {
-		t.rwops.Close()
-	}
-
 	if t.font != nil {
-		t.font.Close()
+		t.assets.ReleaseFont(t.font)
+		t.font = nil
 	}
 
 	if t.renderedText != nil {
 		t.renderedText.Destroy()
+		t.renderedText = nil
 	}
 }
 
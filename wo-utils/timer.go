@@ -0,0 +1,203 @@
+package woutils
+
+import (
+	"math"
+	"slices"
+)
+
+// Timer runs a function once after a delay, or repeatedly at an interval, in the main loop
+// (see TimerGroup.After and TimerGroup.Every). Unlike time.AfterFunc, the function runs in the
+// main thread, so it can use SDL, and the time stops while the game is paused (see GameContext.SetPaused)
+type Timer struct {
+	callback    func()
+	interval    float64 // Milliseconds between runs, after the first one
+	nextRun     float64 // Elapsed time of the next run
+	elapsed     float64
+	runs        int
+	maxRuns     int // 0 runs until cancelled
+	useRealTime bool
+	isCancelled bool
+	isFinished  bool
+}
+
+// SetUseRealTime makes the timer ignore the pause and the time scale of the game (e.g. for pause menus)
+func (t *Timer) SetUseRealTime(useRealTime bool) {
+	t.useRealTime = useRealTime
+}
+
+// Cancel stops the timer. The function is not called again
+func (t *Timer) Cancel() {
+	t.isCancelled = true
+}
+
+// IsActive reports whether the timer will still run
+func (t *Timer) IsActive() bool {
+	return !t.isCancelled && !t.isFinished
+}
+
+// GetRuns returns how many times the function ran
+func (t *Timer) GetRuns() int {
+	return t.runs
+}
+
+// GetRemaining returns the milliseconds until the next run
+func (t *Timer) GetRemaining() uint64 {
+	return uint64(max(t.nextRun-t.elapsed, 0))
+}
+
+// advance advances the timer, running the function once for each run due
+func (t *Timer) advance(deltaMs float64) {
+	t.elapsed += deltaMs
+	for t.IsActive() && t.elapsed >= t.nextRun {
+		t.runs++
+		if t.maxRuns > 0 && t.runs >= t.maxRuns {
+			t.isFinished = true
+		}
+		t.callback()
+
+		if t.interval <= 0 {
+			t.nextRun = math.Nextafter(t.elapsed, math.Inf(1)) // Runs once a frame, while the time passes
+			break
+		}
+		t.nextRun += t.interval
+	}
+}
+
+// TimerGroup keeps timers owned by something (e.g. a scene or a map), cancelled together when it is destroyed.
+// GameContext.After and GameContext.Every use the group of the context
+type TimerGroup struct {
+	timers      []*Timer
+	isPaused    bool
+	isDestroyed bool
+}
+
+// NewTimerGroup creates a group of timers run by the main loop. Destroy it with its owner
+func (gc *GameContext) NewTimerGroup() *TimerGroup {
+	group := &TimerGroup{}
+	gc.timerGroups = append(gc.timerGroups, group)
+	return group
+}
+
+func (tg *TimerGroup) add(timer *Timer) *Timer {
+	if tg.isDestroyed {
+		timer.isCancelled = true
+	}
+	tg.timers = append(tg.timers, timer)
+	return timer
+}
+
+// After runs the function once, after delayMs milliseconds
+func (tg *TimerGroup) After(delayMs uint64, callback func()) *Timer {
+	return tg.add(&Timer{callback: callback, nextRun: float64(delayMs), maxRuns: 1})
+}
+
+// Every runs the function every intervalMs milliseconds, until the timer is cancelled
+func (tg *TimerGroup) Every(intervalMs uint64, callback func()) *Timer {
+	return tg.Repeat(intervalMs, 0, callback)
+}
+
+// Repeat runs the function every intervalMs milliseconds, count times (0 until the timer is cancelled)
+func (tg *TimerGroup) Repeat(intervalMs uint64, count int, callback func()) *Timer {
+	return tg.add(&Timer{callback: callback, interval: float64(intervalMs), nextRun: float64(intervalMs), maxRuns: count})
+}
+
+// SetPaused pauses only the timers of the group
+func (tg *TimerGroup) SetPaused(isPaused bool) {
+	tg.isPaused = isPaused
+}
+
+func (tg *TimerGroup) IsPaused() bool {
+	return tg.isPaused
+}
+
+// GetActiveCount returns how many timers of the group will still run
+func (tg *TimerGroup) GetActiveCount() int {
+	count := 0
+	for _, timer := range tg.timers {
+		if timer.IsActive() {
+			count++
+		}
+	}
+	return count
+}
+
+// CancelAll cancels the timers of the group. The group can still be used
+func (tg *TimerGroup) CancelAll() {
+	for _, timer := range tg.timers {
+		timer.Cancel()
+	}
+}
+
+// Destroy cancels the timers of the group, and removes it from the main loop
+func (tg *TimerGroup) Destroy() {
+	tg.CancelAll()
+	tg.isDestroyed = true
+}
+
+// update advances the timers of the group, removing the ones that ended
+func (tg *TimerGroup) update(gameDeltaMs, realDeltaMs float64) {
+	if tg.isPaused {
+		return
+	}
+
+	// Timers may create other timers, so the length is read in each iteration
+	for i := 0; i < len(tg.timers); i++ {
+		timer := tg.timers[i]
+		if timer.useRealTime {
+			timer.advance(realDeltaMs)
+		} else {
+			timer.advance(gameDeltaMs)
+		}
+	}
+
+	tg.timers = slices.DeleteFunc(tg.timers, func(timer *Timer) bool {
+		return !timer.IsActive()
+	})
+}
+
+// After runs the function once in the main loop, after delayMs milliseconds of game time
+func (gc *GameContext) After(delayMs uint64, callback func()) *Timer {
+	return gc.timers.After(delayMs, callback)
+}
+
+// Every runs the function in the main loop every intervalMs milliseconds of game time, until the timer is cancelled
+func (gc *GameContext) Every(intervalMs uint64, callback func()) *Timer {
+	return gc.timers.Every(intervalMs, callback)
+}
+
+// SetPaused pauses the game time: timers and tweens stop, unless they use the real time
+func (gc *GameContext) SetPaused(isPaused bool) {
+	gc.isPaused = isPaused
+}
+
+func (gc *GameContext) IsPaused() bool {
+	return gc.isPaused
+}
+
+// SetTimeScale sets how fast the game time passes (e.g. 0.5 for slow motion). 1 by default
+func (gc *GameContext) SetTimeScale(timeScale float64) {
+	gc.timeScale = max(timeScale, 0)
+}
+
+func (gc *GameContext) GetTimeScale() float64 {
+	return gc.timeScale
+}
+
+// GetGameTime returns the milliseconds of game time passed, without the pauses and with the time scale
+func (gc *GameContext) GetGameTime() uint64 {
+	return uint64(gc.gameTime)
+}
+
+// updateTimers advances the timers of all the groups, removing the groups destroyed
+func (gc *GameContext) updateTimers(gameDeltaMs, realDeltaMs float64) {
+	gc.timers.update(gameDeltaMs, realDeltaMs)
+
+	// Timers may create other groups, so the length is read in each iteration
+	for i := 0; i < len(gc.timerGroups); i++ {
+		gc.timerGroups[i].update(gameDeltaMs, realDeltaMs)
+	}
+
+	gc.timerGroups = slices.DeleteFunc(gc.timerGroups, func(group *TimerGroup) bool {
+		return group.isDestroyed
+	})
+}
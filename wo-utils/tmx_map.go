@@ -1,10 +1,22 @@
 package woutils
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
 	"log"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
 )
 
 type TsxTileSet struct {
@@ -20,64 +32,485 @@ type TsxTileSet struct {
 		Width  int    `xml:"width,attr"`
 		Height int    `xml:"height,attr"`
 	} `xml:"image"`
+	Tiles    []TsxTile    `xml:"tile"`
+	WangSets []TsxWangSet `xml:"wangsets>wangset"`
+}
+
+// TsxTile is the extra data a tileset defines for one of its tiles
+type TsxTile struct {
+	Id          int             `xml:"id,attr"`
+	Type        string          `xml:"type,attr"`
+	Properties  *TmxProperties  `xml:"properties"`
+	ObjectGroup *TmxObjectGroup `xml:"objectgroup"` // Collision shapes edited in the Tiled collision editor
+}
+
+// TsxWangSet describes the terrain transitions of a tileset (Tiled "Terrain Sets").
+// Type is "corner", "edge" or "mixed"
+type TsxWangSet struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Tile       int            `xml:"tile,attr"` // Tile that represents the set in the editor, -1 if none
+	Properties *TmxProperties `xml:"properties"`
+	Colors     []TsxWangColor `xml:"wangcolor"`
+	Tiles      []TsxWangTile  `xml:"wangtile"`
+}
+
+// TsxWangColor is one of the terrains of a wang set. Colors are referenced by index, starting at 1
+type TsxWangColor struct {
+	Name        string         `xml:"name,attr"`
+	Color       string         `xml:"color,attr"`
+	Tile        int            `xml:"tile,attr"`
+	Probability float64        `xml:"probability,attr"`
+	Properties  *TmxProperties `xml:"properties"`
+}
+
+// TsxWangTile gives the terrain of each edge and corner of a tile (see WangId)
+type TsxWangTile struct {
+	TileId int    `xml:"tileid,attr"`
+	WangId string `xml:"wangid,attr"`
+}
+
+// TmxRawElement keeps an element the engine doesn't understand, so it can be written back untouched
+type TmxRawElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",innerxml"`
+}
+
+type TmxProperty struct {
+	Name         string          `xml:"name,attr"`
+	Type         string          `xml:"type,attr,omitempty"`
+	PropertyType string          `xml:"propertytype,attr,omitempty"`
+	Value        string          `xml:"value,attr,omitempty"`
+	ExtraAttrs   []xml.Attr      `xml:",any,attr"`
+	Content      string          `xml:",chardata"` // Multiline string properties are stored as content
+	Extra        []TmxRawElement `xml:",any"`      // Class properties have nested properties
+}
+
+type TmxProperties struct {
+	Properties []TmxProperty `xml:"property"`
+}
+
+// Get returns the value of the property named name, and false if there is no such property
+func (tp *TmxProperties) Get(name string) (string, bool) {
+	if tp == nil {
+		return "", false
+	}
+
+	for _, property := range tp.Properties {
+		if property.Name == name {
+			if property.Value == "" && property.Content != "" {
+				return property.Content, true
+			}
+			return property.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set changes the value of the property named name, adding it if there is no such property
+func (tp *TmxProperties) Set(name string, value string) {
+	for index := range tp.Properties {
+		if tp.Properties[index].Name == name {
+			tp.Properties[index].Value = value
+			tp.Properties[index].Content = ""
+			return
+		}
+	}
+	tp.Properties = append(tp.Properties, TmxProperty{Name: name, Value: value})
+}
+
+func (tp *TmxProperties) clone() *TmxProperties {
+	if tp == nil {
+		return nil
+	}
+	return &TmxProperties{Properties: slices.Clone(tp.Properties)}
+}
+
+// GetBool returns the property parsed as a bool, or defaultValue if it doesn't exist or isn't a bool
+func (tp *TmxProperties) GetBool(name string, defaultValue bool) bool {
+	if value, ok := tp.Get(name); ok {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// GetFloat returns the property parsed as a float, or defaultValue if it doesn't exist or isn't a number
+func (tp *TmxProperties) GetFloat(name string, defaultValue float64) float64 {
+	if value, ok := tp.Get(name); ok {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// GetInt returns the property parsed as an int, or defaultValue if it doesn't exist or isn't an integer
+func (tp *TmxProperties) GetInt(name string, defaultValue int) int {
+	if value, ok := tp.Get(name); ok {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+type TmxTileSetRef struct {
+	FirstGid   int             `xml:"firstgid,attr"`
+	Source     string          `xml:"source,attr,omitempty"`
+	ExtraAttrs []xml.Attr      `xml:",any,attr"`
+	Extra      []TmxRawElement `xml:",any"` // Content of embedded tilesets
+	TsxPath    string          `xml:"-"`
+	TsxData    *TsxTileSet     `xml:"-"`
+}
+
+type TmxLayerData struct {
+	Encoding    string          `xml:"encoding,attr,omitempty"`
+	Compression string          `xml:"compression,attr,omitempty"`
+	Tiles       []uint32        `xml:"-"` // Filled with data from the Data.Content after UnmarshalXML (GIDs, including flip flags)
+	Content     string          `xml:",chardata"`
+	Extra       []TmxRawElement `xml:",any"` // Chunks of infinite maps and XML encoded tiles
+}
+
+func (tld TmxLayerData) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	start.Attr = nil
+	if tld.Encoding != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "encoding"}, Value: tld.Encoding})
+	}
+	if tld.Compression != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "compression"}, Value: tld.Compression})
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	// Written as a token (instead of a chardata field) so the line breaks of the CSV data aren't escaped
+	if strings.TrimSpace(tld.Content) != "" {
+		if err := encoder.EncodeToken(xml.CharData(tld.Content)); err != nil {
+			return err
+		}
+	}
+
+	for index := range tld.Extra {
+		if err := encoder.EncodeElement(&tld.Extra[index], xml.StartElement{Name: tld.Extra[index].XMLName}); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+type TmxLayer struct {
+	Id         int             `xml:"id,attr"`
+	Name       string          `xml:"name,attr"`
+	Width      int             `xml:"width,attr"`
+	Height     int             `xml:"height,attr"`
+	ExtraAttrs []xml.Attr      `xml:",any,attr"`
+	Properties *TmxProperties  `xml:"properties"`
+	Data       TmxLayerData    `xml:"data"`
+	Extra      []TmxRawElement `xml:",any"`
+}
+
+type TmxPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+type TmxObject struct {
+	Id         int             `xml:"id,attr"`
+	Name       string          `xml:"name,attr,omitempty"`
+	Type       string          `xml:"type,attr,omitempty"`
+	Gid        uint32          `xml:"gid,attr,omitempty"`
+	X          float64         `xml:"x,attr"`
+	Y          float64         `xml:"y,attr"`
+	Width      float64         `xml:"width,attr,omitempty"`
+	Height     float64         `xml:"height,attr,omitempty"`
+	Rotation   float64         `xml:"rotation,attr,omitempty"`
+	ExtraAttrs []xml.Attr      `xml:",any,attr"`
+	Properties *TmxProperties  `xml:"properties"`
+	Ellipse    *struct{}       `xml:"ellipse"`
+	Point      *struct{}       `xml:"point"`
+	Polygon    *TmxPoints      `xml:"polygon"`
+	Polyline   *TmxPoints      `xml:"polyline"`
+	Extra      []TmxRawElement `xml:",any"`
+}
+
+type TmxObjectGroup struct {
+	Id         int             `xml:"id,attr"`
+	Name       string          `xml:"name,attr"`
+	ExtraAttrs []xml.Attr      `xml:",any,attr"`
+	Properties *TmxProperties  `xml:"properties"`
+	Objects    []TmxObject     `xml:"object"`
+	Extra      []TmxRawElement `xml:",any"`
+}
+
+// GetObject returns the object named name, or nil if there is no such object
+func (tog *TmxObjectGroup) GetObject(name string) *TmxObject {
+	for index := range tog.Objects {
+		if tog.Objects[index].Name == name {
+			return &tog.Objects[index]
+		}
+	}
+	return nil
+}
+
+// GetColor returns the property parsed as a Tiled color ("#AARRGGBB" or "#RRGGBB"),
+// or defaultValue if it doesn't exist or isn't a color
+func (tp *TmxProperties) GetColor(name string, defaultValue sdl.Color) sdl.Color {
+	value, ok := tp.Get(name)
+	hexValue, found := strings.CutPrefix(value, "#")
+	if !ok || !found || (len(hexValue) != 6 && len(hexValue) != 8) {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseUint(hexValue, 16, 32)
+	if err != nil {
+		return defaultValue
+	}
+
+	alpha := uint8(255)
+	if len(hexValue) == 8 {
+		alpha = uint8(parsed >> 24)
+	}
+	return sdl.Color{R: uint8(parsed >> 16), G: uint8(parsed >> 8), B: uint8(parsed), A: alpha}
 }
 
 type TmxMap struct {
-	XMLName      xml.Name `xml:"map"`
-	Version      string   `xml:"version,attr"`
-	TiledVersion string   `xml:"tiledversion,attr"`
-	Orientation  string   `xml:"orientation,attr"`
-	RenderOrder  string   `xml:"renderorder,attr"`
-	Width        int      `xml:"width,attr"`
-	Height       int      `xml:"height,attr"`
-	TileWidth    int      `xml:"tilewidth,attr"`
-	TileHeight   int      `xml:"tileheight,attr"`
-	Infinite     int      `xml:"infinite,attr"`
-	NextLayerId  int      `xml:"nextlayerid,attr"`
-	NextObjectId int      `xml:"nextobjectid,attr"`
-
-	TileSets []struct {
-		FirstGid int    `xml:"firstgid,attr"`
-		Source   string `xml:"source,attr"`
-		TsxPath  string
-		TsxData  *TsxTileSet
-	} `xml:"tileset"`
-	Layers []struct {
-		Id     int    `xml:"id,attr"`
-		Name   string `xml:"name,attr"`
-		Width  int    `xml:"width,attr"`
-		Height int    `xml:"height,attr"`
-		Data   struct {
-			Encoding string  `xml:"encoding,attr"`
-			Tiles    []int32 `xml:"-"` // Filled with data from the Data.Content after UnmarshalXML
-			Content  string  `xml:",chardata"`
-		} `xml:"data"`
-	} `xml:"layer"`
+	XMLName      xml.Name   `xml:"map"`
+	Version      string     `xml:"version,attr"`
+	TiledVersion string     `xml:"tiledversion,attr"`
+	Orientation  string     `xml:"orientation,attr"`
+	RenderOrder  string     `xml:"renderorder,attr"`
+	Width        int        `xml:"width,attr"`
+	Height       int        `xml:"height,attr"`
+	TileWidth    int        `xml:"tilewidth,attr"`
+	TileHeight   int        `xml:"tileheight,attr"`
+	Infinite     int        `xml:"infinite,attr"`
+	NextLayerId  int        `xml:"nextlayerid,attr"`
+	NextObjectId int        `xml:"nextobjectid,attr"`
+	ExtraAttrs   []xml.Attr `xml:",any,attr"`
+
+	Properties   *TmxProperties   `xml:"properties"`
+	TileSets     []TmxTileSetRef  `xml:"tileset"`
+	Layers       []TmxLayer       `xml:"layer"`
+	ObjectGroups []TmxObjectGroup `xml:"objectgroup"`
+	Extra        []TmxRawElement  `xml:",any"` // Image layers, groups and anything else
+
+	// Names of the children in the order they were found in the file ("" for Extra),
+	// so layers and object groups keep their drawing order when saved
+	childOrder []string
+}
+
+// tmxMapFields has the same fields as TmxMap, without the custom XML (un)marshalling
+type tmxMapFields TmxMap
+
+// xmlTokenList is a xml.TokenReader over a fixed list of tokens
+type xmlTokenList []xml.Token
+
+func (tl *xmlTokenList) Token() (xml.Token, error) {
+	if len(*tl) == 0 {
+		return nil, io.EOF
+	}
+
+	token := (*tl)[0]
+	*tl = (*tl)[1:]
+	return token, nil
+}
+
+func (tm *TmxMap) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	// Decodes only the attributes here. The children are decoded one by one below to keep their order
+	attrDecoder := xml.NewTokenDecoder(&xmlTokenList{start, start.End()})
+	if err := attrDecoder.Decode((*tmxMapFields)(tm)); err != nil {
+		return err
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch element := token.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			name := element.Name.Local
+			switch name {
+			case "properties":
+				tm.Properties = &TmxProperties{}
+				err = decoder.DecodeElement(tm.Properties, &element)
+			case "tileset":
+				tm.TileSets = append(tm.TileSets, TmxTileSetRef{})
+				err = decoder.DecodeElement(&tm.TileSets[len(tm.TileSets)-1], &element)
+			case "layer":
+				tm.Layers = append(tm.Layers, TmxLayer{})
+				err = decoder.DecodeElement(&tm.Layers[len(tm.Layers)-1], &element)
+			case "objectgroup":
+				tm.ObjectGroups = append(tm.ObjectGroups, TmxObjectGroup{})
+				err = decoder.DecodeElement(&tm.ObjectGroups[len(tm.ObjectGroups)-1], &element)
+			default:
+				name = ""
+				tm.Extra = append(tm.Extra, TmxRawElement{})
+				err = decoder.DecodeElement(&tm.Extra[len(tm.Extra)-1], &element)
+			}
+
+			if err != nil {
+				return err
+			}
+			tm.childOrder = append(tm.childOrder, name)
+		}
+	}
+}
+
+func (tm TmxMap) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "map"}
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "version"}, Value: tm.Version},
+		{Name: xml.Name{Local: "tiledversion"}, Value: tm.TiledVersion},
+		{Name: xml.Name{Local: "orientation"}, Value: tm.Orientation},
+		{Name: xml.Name{Local: "renderorder"}, Value: tm.RenderOrder},
+		{Name: xml.Name{Local: "width"}, Value: strconv.Itoa(tm.Width)},
+		{Name: xml.Name{Local: "height"}, Value: strconv.Itoa(tm.Height)},
+		{Name: xml.Name{Local: "tilewidth"}, Value: strconv.Itoa(tm.TileWidth)},
+		{Name: xml.Name{Local: "tileheight"}, Value: strconv.Itoa(tm.TileHeight)},
+		{Name: xml.Name{Local: "infinite"}, Value: strconv.Itoa(tm.Infinite)},
+		{Name: xml.Name{Local: "nextlayerid"}, Value: strconv.Itoa(tm.NextLayerId)},
+		{Name: xml.Name{Local: "nextobjectid"}, Value: strconv.Itoa(tm.NextObjectId)},
+	}
+	start.Attr = append(start.Attr, tm.ExtraAttrs...)
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	// Each entry of childOrder refers to the next unwritten child of that kind.
+	// Children added after loading (or maps built in code) are written after the known ones,
+	// except the tilesets, which are written before the first layer
+	written := map[string]int{}
+	writeNext := func(name string) error {
+		index := written[name]
+		written[name]++
+
+		switch name {
+		case "properties":
+			if index == 0 && tm.Properties != nil {
+				return encoder.EncodeElement(tm.Properties, xml.StartElement{Name: xml.Name{Local: "properties"}})
+			}
+		case "tileset":
+			if index < len(tm.TileSets) {
+				return encoder.EncodeElement(&tm.TileSets[index], xml.StartElement{Name: xml.Name{Local: "tileset"}})
+			}
+		case "layer":
+			if index < len(tm.Layers) {
+				return encoder.EncodeElement(&tm.Layers[index], xml.StartElement{Name: xml.Name{Local: "layer"}})
+			}
+		case "objectgroup":
+			if index < len(tm.ObjectGroups) {
+				return encoder.EncodeElement(&tm.ObjectGroups[index], xml.StartElement{Name: xml.Name{Local: "objectgroup"}})
+			}
+		default:
+			if index < len(tm.Extra) {
+				return encoder.EncodeElement(&tm.Extra[index], xml.StartElement{Name: tm.Extra[index].XMLName})
+			}
+		}
+		return nil
+	}
+	writeRemaining := func(name string, count int) error {
+		for written[name] < count {
+			if err := writeNext(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	writeHeader := func() error {
+		if tm.Properties != nil {
+			if err := writeRemaining("properties", 1); err != nil {
+				return err
+			}
+		}
+		return writeRemaining("tileset", len(tm.TileSets))
+	}
+
+	for _, name := range tm.childOrder {
+		if name == "layer" || name == "objectgroup" || name == "" {
+			if err := writeHeader(); err != nil {
+				return err
+			}
+		}
+
+		if err := writeNext(name); err != nil {
+			return err
+		}
+	}
+
+	if err := writeHeader(); err != nil {
+		return err
+	}
+	if err := writeRemaining("layer", len(tm.Layers)); err != nil {
+		return err
+	}
+	if err := writeRemaining("objectgroup", len(tm.ObjectGroups)); err != nil {
+		return err
+	}
+	if err := writeRemaining("", len(tm.Extra)); err != nil {
+		return err
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// GetObjectGroup returns the object group (object layer) named name, or nil if there is no such group
+func (tm *TmxMap) GetObjectGroup(name string) *TmxObjectGroup {
+	for index := range tm.ObjectGroups {
+		if tm.ObjectGroups[index].Name == name {
+			return &tm.ObjectGroups[index]
+		}
+	}
+	return nil
 }
 
 type TiledMap struct {
 	path   string
+	fsys   fs.FS // Where the map, its tilesets and their images are read from
 	TmxMap TmxMap
 }
 
 func NewTiledMap(path string) TiledMap {
-	var tmxMap TmxMap
-	if err := ReadXml(path, &tmxMap); err != nil {
+	return NewTiledMapFromFs(osFS, path)
+}
+
+// NewTiledMapFromFs loads a TMX file of fsys.
+// The TSX files and images it references are read from fsys, relative to the file that references them
+func NewTiledMapFromFs(fsys fs.FS, path string) TiledMap {
+	tiledMap, err := loadTiledMap(fsys, path)
+	if err != nil {
 		log.Fatalln(err)
 	}
+	return tiledMap
+}
+
+// loadTiledMap reads a TMX file and its TSX files. It does not use SDL, so it can run in any goroutine
+func loadTiledMap(fsys fs.FS, path string) (TiledMap, error) {
+	var tmxMap TmxMap
+	if err := ReadXmlFromFs(fsys, path, &tmxMap); err != nil {
+		return TiledMap{}, err
+	}
 
 	if err := processTiles(&tmxMap); err != nil {
-		log.Fatalln("Failed to process tilemap: ", err)
+		return TiledMap{}, fmt.Errorf("failed to process tilemap %s: %w", path, err)
 	}
 
 	for tilesetIndex := range tmxMap.TileSets {
 		tileset := &tmxMap.TileSets[tilesetIndex] // Using pointer to update the original struct
 
 		if tileset.Source != "" {
-			tileset.TsxPath = AppendOnPath(GetDirFromPath(path), tileset.Source)
+			tileset.TsxPath = ResolvePath(path, tileset.Source)
 			var tsxTileSet TsxTileSet
-			if err := ReadXml(tileset.TsxPath, &tsxTileSet); err != nil {
-				log.Fatalln(err)
+			if err := ReadXmlFromFs(fsys, tileset.TsxPath, &tsxTileSet); err != nil {
+				return TiledMap{}, err
 			}
 
 			tileset.TsxData = &tsxTileSet
@@ -86,19 +519,30 @@ func NewTiledMap(path string) TiledMap {
 
 	return TiledMap{
 		path:   path,
+		fsys:   fsys,
 		TmxMap: tmxMap,
-	}
+	}, nil
+}
+
+func (tm *TiledMap) GetPath() string {
+	return tm.path
+}
+
+// GetFs returns the file system the map was loaded from
+func (tm *TiledMap) GetFs() fs.FS {
+	return tm.fsys
 }
 
 func processTiles(tmxMap *TmxMap) error {
 	for layerIndex := range tmxMap.Layers {
 		layer := &tmxMap.Layers[layerIndex] // Using pointer to update the original struct
 
-		if layer.Data.Encoding == "csv" {
+		switch layer.Data.Encoding {
+		case "csv":
 			content := strings.TrimSpace(layer.Data.Content)
 			tileStrings := strings.Split(content, ",")
 
-			tiles := make([]int32, 0, len(tileStrings))
+			tiles := make([]uint32, 0, len(tileStrings))
 
 			for _, tile := range tileStrings {
 				tile = strings.TrimSpace(tile)
@@ -106,15 +550,194 @@ func processTiles(tmxMap *TmxMap) error {
 					continue
 				}
 
-				tileID, err := strconv.Atoi(tile)
+				// GIDs are unsigned because the highest bits hold the flip flags
+				tileID, err := strconv.ParseUint(tile, 10, 32)
 				if err != nil {
 					return err
 				}
-				tiles = append(tiles, int32(tileID))
+				tiles = append(tiles, uint32(tileID))
 			}
 
 			layer.Data.Tiles = tiles
+		case "base64":
+			tiles, err := decodeBase64Tiles(layer.Data.Content, layer.Data.Compression)
+			if err != nil {
+				return fmt.Errorf("layer %s: %w", layer.Name, err)
+			}
+
+			layer.Data.Tiles = tiles
+		}
+	}
+	return nil
+}
+
+// decodeBase64Tiles decodes the base64 layer data: an array of little-endian uint32 GIDs,
+// optionally compressed with zlib or gzip
+func decodeBase64Tiles(content string, compression string) ([]uint32, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	switch compression {
+	case "":
+		reader = bytes.NewReader(data)
+	case "zlib":
+		if reader, err = zlib.NewReader(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	case "gzip":
+		if reader, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tile layer compression: %s", compression)
+	}
+
+	if data, err = io.ReadAll(reader); err != nil {
+		return nil, err
+	}
+
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("invalid base64 tile data length: %d", len(data))
+	}
+
+	tiles := make([]uint32, len(data)/4)
+	for index := range tiles {
+		tiles[index] = binary.LittleEndian.Uint32(data[index*4:])
+	}
+
+	return tiles, nil
+}
+
+type TmxEncoding uint8
+
+const (
+	TmxKeepEncoding TmxEncoding = iota // Keeps the encoding each layer had when loaded
+	TmxCsvEncoding
+	TmxBase64Encoding
+	TmxBase64ZlibEncoding
+	TmxBase64GzipEncoding
+)
+
+// Save writes the map to a TMX file, encoding the tile layers with the given encoding.
+// Tileset references are rewritten relative to the new file location, for maps of the OS file system
+// (the paths of other file systems mean nothing next to the new file, so they are kept as loaded).
+// The map itself is not changed, so later saves with TmxKeepEncoding keep the encodings the map was loaded with
+func (tm *TiledMap) Save(path string, encoding TmxEncoding) error {
+	tmxMap := tm.TmxMap
+	tmxMap.Layers = slices.Clone(tm.TmxMap.Layers)
+	for layerIndex := range tmxMap.Layers {
+		if err := encodeTiles(&tmxMap.Layers[layerIndex], encoding); err != nil {
+			return err
+		}
+	}
+
+	if tm.fsys != nil && tm.fsys != osFS {
+		return WriteXml(path, &tmxMap)
+	}
+
+	saveDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	tmxMap.TileSets = slices.Clone(tm.TmxMap.TileSets)
+	for tilesetIndex := range tmxMap.TileSets {
+		tileset := &tmxMap.TileSets[tilesetIndex] // Using pointer to update the copied struct
+
+		if tileset.Source == "" || tileset.TsxPath == "" {
+			continue
+		}
+
+		tsxPath, err := filepath.Abs(filepath.FromSlash(tileset.TsxPath))
+		if err != nil {
+			return fmt.Errorf("tileset %s: %w", tileset.TsxPath, err)
+		}
+		source, err := filepath.Rel(saveDir, tsxPath)
+		if err != nil {
+			return fmt.Errorf("tileset %s: %w", tileset.TsxPath, err)
+		}
+		tileset.Source = filepath.ToSlash(source)
+	}
+
+	return WriteXml(path, &tmxMap)
+}
+
+// encodeTiles fills the layer Data.Content with its tiles. Layers without decoded tiles
+// (e.g. chunks of infinite maps) are kept as they were loaded
+func encodeTiles(layer *TmxLayer, encoding TmxEncoding) error {
+	data := &layer.Data
+	if data.Tiles == nil {
+		return nil
+	}
+
+	if encoding == TmxKeepEncoding {
+		switch {
+		case data.Encoding == "csv":
+			encoding = TmxCsvEncoding
+		case data.Encoding == "base64" && data.Compression == "":
+			encoding = TmxBase64Encoding
+		case data.Encoding == "base64" && data.Compression == "zlib":
+			encoding = TmxBase64ZlibEncoding
+		case data.Encoding == "base64" && data.Compression == "gzip":
+			encoding = TmxBase64GzipEncoding
+		default:
+			return fmt.Errorf("layer %s: unsupported tile layer encoding: %s %s", layer.Name, data.Encoding, data.Compression)
+		}
+	}
+
+	if encoding == TmxCsvEncoding {
+		var content strings.Builder
+		content.WriteString("\n")
+		for index, tile := range data.Tiles {
+			content.WriteString(strconv.FormatUint(uint64(tile), 10))
+			if index < len(data.Tiles)-1 {
+				content.WriteString(",")
+			}
+			if layer.Width > 0 && (index+1)%layer.Width == 0 {
+				content.WriteString("\n")
+			}
 		}
+
+		data.Encoding = "csv"
+		data.Compression = ""
+		data.Content = content.String()
+		return nil
 	}
+
+	raw := make([]byte, len(data.Tiles)*4)
+	for index, tile := range data.Tiles {
+		binary.LittleEndian.PutUint32(raw[index*4:], tile)
+	}
+
+	var compressed bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case TmxBase64Encoding:
+		data.Compression = ""
+	case TmxBase64ZlibEncoding:
+		data.Compression = "zlib"
+		writer = zlib.NewWriter(&compressed)
+	case TmxBase64GzipEncoding:
+		data.Compression = "gzip"
+		writer = gzip.NewWriter(&compressed)
+	default:
+		return fmt.Errorf("invalid tile layer encoding: %d", encoding)
+	}
+
+	if writer != nil {
+		if _, err := writer.Write(raw); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		raw = compressed.Bytes()
+	}
+
+	data.Encoding = "base64"
+	data.Content = "\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
 	return nil
 }
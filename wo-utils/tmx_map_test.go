@@ -0,0 +1,145 @@
+package woutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+const testTsx = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset name="tiles" tilewidth="64" tileheight="32" tilecount="4" columns="2">
+ <image source="tiles.png" width="128" height="64"/>
+</tileset>
+`
+
+const testTmx = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" tiledversion="1.11.0" orientation="isometric" renderorder="right-down" width="2" height="2" tilewidth="64" tileheight="32" infinite="0" nextlayerid="3" nextobjectid="1">
+ <tileset firstgid="1" source="tilesets/tiles.tsx"/>
+ <layer id="1" name="Ground" width="2" height="2">
+  <data encoding="csv">
+1,2,
+3,4
+</data>
+ </layer>
+ <layer id="2" name="Props" width="2" height="2">
+  <data>
+   <tile gid="1"/>
+   <tile/>
+   <tile/>
+   <tile gid="2"/>
+  </data>
+ </layer>
+</map>
+`
+
+// writeTestMap writes the test map and its tileset to a new directory, returning the path of the map
+func writeTestMap(t *testing.T) string {
+	directory := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(directory, "tilesets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(directory, "tilesets", "tiles.tsx"), []byte(testTsx), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(directory, "map.tmx"), []byte(testTmx), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(directory, "map.tmx")
+}
+
+func TestTiledMapSaveKeepsEncodings(t *testing.T) {
+	tiledMap := NewTiledMap(writeTestMap(t))
+	directory := t.TempDir()
+
+	if err := tiledMap.Save(filepath.Join(directory, "zlib.tmx"), TmxBase64ZlibEncoding); err != nil {
+		t.Fatal(err)
+	}
+	if err := tiledMap.Save(filepath.Join(directory, "kept.tmx"), TmxKeepEncoding); err != nil {
+		t.Fatal(err)
+	}
+
+	zlibMap := NewTiledMap(filepath.Join(directory, "zlib.tmx"))
+	keptMap := NewTiledMap(filepath.Join(directory, "kept.tmx"))
+
+	tests := []struct {
+		name        string
+		data        TmxLayerData
+		encoding    string
+		compression string
+		tiles       []uint32
+	}{
+		{"zlib ground", zlibMap.TmxMap.Layers[0].Data, "base64", "zlib", []uint32{1, 2, 3, 4}},
+		{"zlib props", zlibMap.TmxMap.Layers[1].Data, "", "", nil},
+		{"kept ground", keptMap.TmxMap.Layers[0].Data, "csv", "", []uint32{1, 2, 3, 4}},
+		{"kept props", keptMap.TmxMap.Layers[1].Data, "", "", nil},
+		{"loaded ground", tiledMap.TmxMap.Layers[0].Data, "csv", "", []uint32{1, 2, 3, 4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.data.Encoding != test.encoding || test.data.Compression != test.compression {
+				t.Errorf("encoding = %q %q, expected %q %q", test.data.Encoding, test.data.Compression, test.encoding, test.compression)
+			}
+			if len(test.data.Tiles) != len(test.tiles) {
+				t.Fatalf("tiles = %v, expected %v", test.data.Tiles, test.tiles)
+			}
+			for index := range test.tiles {
+				if test.data.Tiles[index] != test.tiles[index] {
+					t.Fatalf("tiles = %v, expected %v", test.data.Tiles, test.tiles)
+				}
+			}
+		})
+	}
+}
+
+func TestTiledMapSaveRewritesTilesetSources(t *testing.T) {
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Loaded from a relative path, saved to an absolute one
+	relativePath, err := filepath.Rel(workingDirectory, writeTestMap(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tiledMap := NewTiledMap(filepath.ToSlash(relativePath))
+
+	savePath := filepath.Join(t.TempDir(), "saves", "map.tmx")
+	if err := os.MkdirAll(filepath.Dir(savePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := tiledMap.Save(savePath, TmxKeepEncoding); err != nil {
+		t.Fatal(err)
+	}
+
+	savedMap := NewTiledMap(savePath)
+	if savedMap.TmxMap.TileSets[0].TsxData == nil || savedMap.TmxMap.TileSets[0].TsxData.TileCount != 4 {
+		t.Fatalf("the saved map does not reference the tileset, source = %s", savedMap.TmxMap.TileSets[0].Source)
+	}
+	if tiledMap.TmxMap.TileSets[0].Source != "tilesets/tiles.tsx" {
+		t.Fatalf("saving changed the source of the loaded map to %s", tiledMap.TmxMap.TileSets[0].Source)
+	}
+}
+
+func TestTiledMapSaveKeepsSourcesOfOtherFs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"maps/map.tmx":            {Data: []byte(testTmx)},
+		"maps/tilesets/tiles.tsx": {Data: []byte(testTsx)},
+	}
+	tiledMap := NewTiledMapFromFs(fsys, "maps/map.tmx")
+
+	savePath := filepath.Join(t.TempDir(), "map.tmx")
+	if err := tiledMap.Save(savePath, TmxKeepEncoding); err != nil {
+		t.Fatal(err)
+	}
+
+	var savedMap TmxMap
+	if err := ReadXml(savePath, &savedMap); err != nil {
+		t.Fatal(err)
+	}
+	if source := savedMap.TileSets[0].Source; source != "tilesets/tiles.tsx" {
+		t.Fatalf("source = %s, expected the source of the loaded map", source)
+	}
+}
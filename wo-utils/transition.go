@@ -0,0 +1,281 @@
+package woutils
+
+import (
+	"log"
+	"math"
+	"math/rand/v2"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+type TransitionKind uint8
+
+const (
+	TransitionFade      TransitionKind = iota // Fades to a color, then from it
+	TransitionCrossfade                       // The old screen fades out over the new one
+	TransitionWipe                            // A band of color crosses the screen, from left to right
+	TransitionIris                            // A circle closes on a point, then opens
+	TransitionDissolve                        // Tiles of color cover the screen at random, then uncover it
+)
+
+const IRIS_SEGMENTS int = 64
+
+// Transition covers the screen while the game switches between scenes or maps (see GameContext.StartTransition).
+// The midpoint function runs when the screen is covered, to unload the old scene and load the new one.
+// A crossfade covers the screen with a picture of the old scene, so its midpoint is at the start
+type Transition struct {
+	kind         TransitionKind
+	duration     uint64 // Milliseconds
+	elapsed      uint64
+	lastTicks    uint64
+	easing       EasingFunc
+	color        sdl.Color
+	tileSize     int32
+	irisX        int32
+	irisY        int32
+	hasIrisPoint bool
+	onMidpoint   func()
+	onComplete   func()
+	midpointDone bool
+	snapshot     *sdl.Texture // Picture of the old scene, for crossfades
+	tileOrder    []int        // Order the tiles cover the screen, for dissolves
+	tileRects    []sdl.Rect
+	vertices     []sdl.Vertex
+	indices      []int32
+}
+
+// NewTransition creates a transition lasting durationMs milliseconds, covering the screen with black
+func NewTransition(kind TransitionKind, durationMs uint64) *Transition {
+	return &Transition{
+		kind:     kind,
+		duration: max(durationMs, 1),
+		easing:   EaseInOutQuad,
+		color:    sdl.Color{R: 0, G: 0, B: 0, A: 255},
+		tileSize: 32,
+	}
+}
+
+func (t *Transition) SetEasing(easing EasingFunc) {
+	t.easing = easing
+}
+
+// SetColor sets the color covering the screen (not used by crossfades)
+func (t *Transition) SetColor(r, g, b uint8) {
+	t.color = sdl.Color{R: r, G: g, B: b, A: 255}
+}
+
+// SetTileSize sets the size of the tiles of dissolves, in pixels
+func (t *Transition) SetTileSize(tileSize int32) {
+	t.tileSize = max(tileSize, 1)
+}
+
+// SetIrisCenter sets the point of the screen the iris closes on (e.g. the player). The window center by default
+func (t *Transition) SetIrisCenter(x, y int32) {
+	t.irisX, t.irisY = x, y
+	t.hasIrisPoint = true
+}
+
+// OnMidpoint sets the function called when the screen is covered (e.g. to replace the GameMap)
+func (t *Transition) OnMidpoint(onMidpoint func()) {
+	t.onMidpoint = onMidpoint
+}
+
+func (t *Transition) OnComplete(onComplete func()) {
+	t.onComplete = onComplete
+}
+
+// GetProgress returns the fraction of the duration elapsed, from 0 to 1
+func (t *Transition) GetProgress() float64 {
+	return min(float64(t.elapsed)/float64(t.duration), 1)
+}
+
+func (t *Transition) isFinished() bool {
+	return t.elapsed >= t.duration
+}
+
+// start prepares the transition, taking the picture of the old scene for crossfades
+func (t *Transition) start(gc *GameContext) {
+	t.elapsed, t.lastTicks, t.midpointDone = 0, 0, false
+
+	if t.kind == TransitionCrossfade {
+		width, height := gc.GetWindowSize()
+		snapshot, err := createTargetTexture(gc.renderer, width, height, "")
+		if err != nil {
+			log.Fatalf("Failed to create texture for the transition: %s", err)
+		}
+
+		previousTarget := gc.renderer.GetRenderTarget()
+		gc.renderer.SetRenderTarget(snapshot)
+		gc.renderScene()
+		gc.renderer.SetRenderTarget(previousTarget)
+		t.snapshot = snapshot
+
+		t.runMidpoint()
+	}
+}
+
+func (t *Transition) runMidpoint() {
+	t.midpointDone = true
+	if t.onMidpoint != nil {
+		t.onMidpoint()
+	}
+	// The time loading the new scene is not part of the transition
+	t.lastTicks = sdl.GetTicks64()
+}
+
+// update advances the time, running the midpoint function when the screen is covered
+func (t *Transition) update() {
+	ticks := sdl.GetTicks64()
+	if t.lastTicks != 0 {
+		t.elapsed = min(t.elapsed+ticks-t.lastTicks, t.duration)
+	}
+	t.lastTicks = ticks
+
+	if !t.midpointDone && t.elapsed*2 >= t.duration {
+		t.elapsed = t.duration / 2 // Drawn fully covered
+		t.runMidpoint()
+	}
+}
+
+// getCoverage returns how much of the screen is covered, from 0 to 1
+func (t *Transition) getCoverage() float64 {
+	progress := t.GetProgress()
+	if t.kind == TransitionCrossfade {
+		return 1 - t.easing(progress)
+	}
+	if progress <= 0.5 {
+		return t.easing(progress * 2)
+	}
+	return t.easing((1 - progress) * 2)
+}
+
+// render draws the cover of the transition over the scene
+func (t *Transition) render(gc *GameContext) {
+	coverage := min(max(t.getCoverage(), 0), 1)
+	if coverage <= 0 {
+		return
+	}
+
+	renderer := gc.renderer
+	red, green, blue, alpha, _ := renderer.GetDrawColor()
+	defer renderer.SetDrawColor(red, green, blue, alpha)
+	var blendMode sdl.BlendMode
+	renderer.GetDrawBlendMode(&blendMode)
+	defer renderer.SetDrawBlendMode(blendMode)
+
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	renderer.SetDrawColor(t.color.R, t.color.G, t.color.B, t.color.A)
+	width, height := gc.GetWindowSize()
+
+	switch t.kind {
+	case TransitionFade:
+		renderer.SetDrawColor(t.color.R, t.color.G, t.color.B, uint8(coverage*255))
+		renderer.FillRect(nil)
+	case TransitionCrossfade:
+		t.snapshot.SetAlphaMod(uint8(coverage * 255))
+		renderer.Copy(t.snapshot, nil, nil)
+	case TransitionWipe:
+		bandWidth := int32(coverage * float64(width))
+		if t.GetProgress() <= 0.5 {
+			renderer.FillRect(&sdl.Rect{X: 0, Y: 0, W: bandWidth, H: height})
+		} else {
+			renderer.FillRect(&sdl.Rect{X: width - bandWidth, Y: 0, W: bandWidth, H: height})
+		}
+	case TransitionIris:
+		t.renderIris(gc, coverage, width, height)
+	case TransitionDissolve:
+		t.renderDissolve(gc, coverage, width, height)
+	}
+}
+
+// renderIris covers everything outside of a circle, as a ring of triangles
+func (t *Transition) renderIris(gc *GameContext, coverage float64, width, height int32) {
+	centerX, centerY := float64(width/2), float64(height/2)
+	if t.hasIrisPoint {
+		centerX, centerY = float64(t.irisX), float64(t.irisY)
+	}
+
+	// The farthest corner from the center is the radius of the open iris
+	outerRadius := math.Max(math.Hypot(centerX, centerY), math.Hypot(float64(width)-centerX, float64(height)-centerY)) + 1
+	innerRadius := outerRadius * (1 - coverage)
+
+	if t.vertices == nil {
+		t.vertices = make([]sdl.Vertex, IRIS_SEGMENTS*2)
+		t.indices = make([]int32, 0, IRIS_SEGMENTS*6)
+		for i := range int32(IRIS_SEGMENTS) {
+			next := (i + 1) % int32(IRIS_SEGMENTS)
+			t.indices = append(t.indices, i*2, i*2+1, next*2, next*2, i*2+1, next*2+1)
+		}
+	}
+
+	for i := range IRIS_SEGMENTS {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(IRIS_SEGMENTS))
+		t.vertices[i*2] = sdl.Vertex{
+			Position: sdl.FPoint{X: float32(centerX + innerRadius*cos), Y: float32(centerY + innerRadius*sin)},
+			Color:    t.color,
+		}
+		t.vertices[i*2+1] = sdl.Vertex{
+			Position: sdl.FPoint{X: float32(centerX + outerRadius*cos), Y: float32(centerY + outerRadius*sin)},
+			Color:    t.color,
+		}
+	}
+	gc.renderer.RenderGeometry(nil, t.vertices, t.indices)
+}
+
+// renderDissolve covers a fraction of the tiles, in a random order chosen once
+func (t *Transition) renderDissolve(gc *GameContext, coverage float64, width, height int32) {
+	columns := (width + t.tileSize - 1) / t.tileSize
+	rows := (height + t.tileSize - 1) / t.tileSize
+	tileCount := int(columns * rows)
+	if len(t.tileOrder) != tileCount {
+		t.tileOrder = rand.Perm(tileCount)
+		t.tileRects = make([]sdl.Rect, 0, tileCount)
+	}
+
+	t.tileRects = t.tileRects[:0]
+	for _, tile := range t.tileOrder[:int(coverage*float64(tileCount))] {
+		col, row := int32(tile)%columns, int32(tile)/columns
+		t.tileRects = append(t.tileRects, sdl.Rect{X: col * t.tileSize, Y: row * t.tileSize, W: t.tileSize, H: t.tileSize})
+	}
+	if len(t.tileRects) > 0 {
+		gc.renderer.FillRects(t.tileRects)
+	}
+}
+
+func (t *Transition) destroy() {
+	if t.snapshot != nil {
+		t.snapshot.Destroy()
+		t.snapshot = nil
+	}
+}
+
+// StartTransition starts covering the screen with the transition. The mouse listeners get no movements
+// nor presses until it ends, only releases.
+// A transition started while another runs replaces it
+func (gc *GameContext) StartTransition(transition *Transition) {
+	if gc.transition != nil {
+		gc.transition.destroy()
+	}
+
+	gc.transition = transition
+	transition.start(gc)
+}
+
+// IsTransitioning reports whether a transition is running
+func (gc *GameContext) IsTransitioning() bool {
+	return gc.transition != nil
+}
+
+// renderTransition advances the transition and draws it over the scene, ending it when finished
+func (gc *GameContext) renderTransition() {
+	transition := gc.transition
+	transition.render(gc)
+
+	if transition.isFinished() {
+		gc.transition = nil
+		transition.destroy()
+		if transition.onComplete != nil {
+			transition.onComplete()
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package woutils
+
+import "slices"
+
+// Tween animates values over time, driven by the game time (see GameContext.PlayTween and GameContext.SetPaused).
+// A tween changes one or more values (see TweenValue and the property tweens, e.g. TweenPosition),
+// waits (NewDelay), or plays other tweens one after another (NewSequence) or together (NewParallel).
+//
+// The start values of the property tweens are read when they start playing, so they continue
+// from where the tweens before them in a sequence left the properties
+type Tween struct {
+	duration     float64 // Milliseconds of one play
+	delay        float64
+	easing       EasingFunc
+	start        func() (set func(progress float64)) // Reads the start values, returns the function changing them
+	set          func(progress float64)
+	children     []*Tween
+	isParallel   bool
+	repeat       int // Plays after the first one, -1 repeats forever
+	yoyo         bool
+	target       any // The object changed, see GameContext.CancelTweensOf
+	useRealTime  bool
+	onComplete   func()
+	elapsed      float64 // Of the current play
+	delayLeft    float64
+	plays        int // Plays finished
+	currentChild int // Playing, in sequences
+	isFinished   bool
+	isCancelled  bool
+}
+
+// newTween creates a tween of a property, changed by the function start returns
+func newTween(target any, durationMs uint64, start func() (set func(progress float64))) *Tween {
+	return &Tween{
+		duration: float64(durationMs),
+		easing:   EaseInOutQuad,
+		start:    start,
+		target:   target,
+	}
+}
+
+// TweenValue changes a value from one number to another, calling set with each value
+func TweenValue(from, to float64, durationMs uint64, set func(value float64)) *Tween {
+	return newTween(nil, durationMs, func() func(progress float64) {
+		return func(progress float64) {
+			set(from + (to-from)*progress)
+		}
+	})
+}
+
+// NewDelay creates a tween that only waits (e.g. between the tweens of a sequence)
+func NewDelay(durationMs uint64) *Tween {
+	return newTween(nil, durationMs, nil)
+}
+
+// NewSequence creates a tween playing the tweens one after another
+func NewSequence(tweens ...*Tween) *Tween {
+	return &Tween{children: tweens, easing: EaseLinear}
+}
+
+// NewParallel creates a tween playing the tweens together. It ends when all of them end
+func NewParallel(tweens ...*Tween) *Tween {
+	return &Tween{children: tweens, isParallel: true, easing: EaseLinear}
+}
+
+// SetEasing sets the curve of the values (EaseInOutQuad by default). Not used by sequences and parallel tweens
+func (t *Tween) SetEasing(easing EasingFunc) {
+	t.easing = easing
+}
+
+// SetDelay sets the time waited before each start of the tween (repeats don't wait)
+func (t *Tween) SetDelay(delayMs uint64) {
+	t.delay = float64(delayMs)
+}
+
+// SetRepeat sets how many times the tween plays again after the first play. -1 repeats forever
+func (t *Tween) SetRepeat(repeat int) {
+	t.repeat = repeat
+}
+
+// SetYoyo makes every other play go backwards, from the end values to the start values.
+// Used by the tweens of values, not by sequences and parallel tweens
+func (t *Tween) SetYoyo(yoyo bool) {
+	t.yoyo = yoyo
+}
+
+// SetUseRealTime makes the tween ignore the pause and the time scale of the game (e.g. menus sliding in
+// while the game is paused). Used by the tweens played with GameContext.PlayTween, not by the tweens inside them
+func (t *Tween) SetUseRealTime(useRealTime bool) {
+	t.useRealTime = useRealTime
+}
+
+// OnComplete sets the function called when the tween ends. Not called if the tween is cancelled
+func (t *Tween) OnComplete(onComplete func()) {
+	t.onComplete = onComplete
+}
+
+// Cancel stops the tween, leaving the values where they are
+func (t *Tween) Cancel() {
+	t.isCancelled = true
+}
+
+func (t *Tween) IsFinished() bool {
+	return t.isFinished
+}
+
+func (t *Tween) IsCancelled() bool {
+	return t.isCancelled
+}
+
+func (t *Tween) isDone() bool {
+	return t.isFinished || t.isCancelled
+}
+
+// reset prepares the tween to play from the beginning. The start values already read are kept,
+// so repeating a sequence plays the same movement again
+func (t *Tween) reset() {
+	t.plays = 0
+	t.delayLeft = t.delay
+	t.isFinished, t.isCancelled = false, false
+	t.resetPlay()
+}
+
+func (t *Tween) resetPlay() {
+	t.elapsed = 0
+	t.currentChild = 0
+	for _, child := range t.children {
+		child.reset()
+	}
+}
+
+// hasTarget reports whether the tween, or one of its children, changes the target
+func (t *Tween) hasTarget(target any) bool {
+	if t.target != nil && t.target == target {
+		return true
+	}
+	for _, child := range t.children {
+		if child.hasTarget(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// advance plays the tween for deltaMs milliseconds. When it ends, returns the time left over,
+// to be used by the next tween of a sequence
+func (t *Tween) advance(deltaMs float64) (leftoverMs float64) {
+	if t.isDone() {
+		return deltaMs
+	}
+
+	if t.delayLeft > 0 {
+		waited := min(deltaMs, t.delayLeft)
+		t.delayLeft -= waited
+		deltaMs -= waited
+		if t.delayLeft > 0 {
+			return 0
+		}
+	}
+
+	if t.set == nil && t.start != nil {
+		t.set = t.start()
+	}
+
+	for {
+		leftover, isPlayFinished := t.advancePlay(deltaMs)
+		if !isPlayFinished || t.isCancelled {
+			return 0
+		}
+
+		t.plays++
+		if t.repeat >= 0 && t.plays > t.repeat {
+			t.isFinished = true
+			if t.onComplete != nil {
+				t.onComplete()
+			}
+			return leftover
+		}
+
+		t.resetPlay()
+		if leftover <= 0 || leftover >= deltaMs {
+			return 0 // The next play starts in the next frame (plays without duration repeat once a frame)
+		}
+		deltaMs = leftover
+	}
+}
+
+// advancePlay advances the current play, returning the time left over when it ends
+func (t *Tween) advancePlay(deltaMs float64) (leftoverMs float64, isPlayFinished bool) {
+	if t.children == nil {
+		t.elapsed += deltaMs
+		progress := 1.0
+		if t.duration > 0 {
+			progress = min(t.elapsed/t.duration, 1)
+		}
+		if t.yoyo && t.plays%2 == 1 {
+			progress = 1 - progress
+		}
+		if t.set != nil {
+			t.set(t.easing(progress))
+		}
+		return t.elapsed - t.duration, t.elapsed >= t.duration
+	}
+
+	if !t.isParallel {
+		for t.currentChild < len(t.children) {
+			child := t.children[t.currentChild]
+			deltaMs = child.advance(deltaMs)
+			if !child.isDone() {
+				return 0, false
+			}
+			t.currentChild++
+		}
+		return deltaMs, true
+	}
+
+	// The play ends with the last child to end, which has the least time left over
+	leftoverMs, isPlayFinished = deltaMs, true
+	for _, child := range t.children {
+		if child.isDone() {
+			continue
+		}
+		childLeftover := child.advance(deltaMs)
+		if child.isDone() {
+			leftoverMs = min(leftoverMs, childLeftover)
+		} else {
+			isPlayFinished = false
+		}
+	}
+	if !isPlayFinished {
+		return 0, false
+	}
+	return leftoverMs, true
+}
+
+// PlayTween starts playing the tween (from the beginning, if it played before)
+func (gc *GameContext) PlayTween(tween *Tween) {
+	tween.reset()
+	if !slices.Contains(gc.tweens, tween) {
+		gc.tweens = append(gc.tweens, tween)
+	}
+}
+
+// CancelTweensOf cancels the tweens changing the target (e.g. before panning the camera again)
+func (gc *GameContext) CancelTweensOf(target any) {
+	for _, tween := range gc.tweens {
+		if tween.hasTarget(target) {
+			tween.Cancel()
+		}
+	}
+}
+
+// CancelTweens cancels all the tweens playing
+func (gc *GameContext) CancelTweens() {
+	for _, tween := range gc.tweens {
+		tween.Cancel()
+	}
+}
+
+// updateTweens advances the tweens playing, removing the ones that ended
+func (gc *GameContext) updateTweens(gameDeltaMs, realDeltaMs float64) {
+	// Tweens may start other tweens when they end, so the length is read in each iteration
+	for i := 0; i < len(gc.tweens); i++ {
+		tween := gc.tweens[i]
+		if tween.useRealTime {
+			tween.advance(realDeltaMs)
+		} else {
+			tween.advance(gameDeltaMs)
+		}
+	}
+
+	playing := gc.tweens[:0]
+	for _, tween := range gc.tweens {
+		if !tween.isDone() {
+			playing = append(playing, tween)
+		}
+	}
+	clear(gc.tweens[len(playing):])
+	gc.tweens = playing
+}
@@ -0,0 +1,137 @@
+package woutils
+
+import (
+	"math"
+
+	womixins "github.com/joaovitor123jv/wo-engine/wo-mixins"
+)
+
+// Positionable is something with a position on the screen (e.g. Image, Text, NineSlice and Button)
+type Positionable interface {
+	GetPosition() (x, y int32)
+	SetPosition(x, y int32)
+}
+
+// Sizable is something with a size on the screen (e.g. Image, Text, NineSlice and Button)
+type Sizable interface {
+	GetSize() (width, height int32)
+	SetSize(width, height int32)
+}
+
+// lerp returns the value at progress between from and to
+func lerp(from, to, progress float64) float64 {
+	return from + (to-from)*progress
+}
+
+// TweenPosition moves the target to a position (e.g. a panel sliding in)
+func TweenPosition(target Positionable, x, y int32, durationMs uint64) *Tween {
+	return newTween(target, durationMs, func() func(progress float64) {
+		fromX, fromY := target.GetPosition()
+		return func(progress float64) {
+			target.SetPosition(
+				int32(math.Round(lerp(float64(fromX), float64(x), progress))),
+				int32(math.Round(lerp(float64(fromY), float64(y), progress))),
+			)
+		}
+	})
+}
+
+// TweenSize resizes the target
+func TweenSize(target Sizable, width, height int32, durationMs uint64) *Tween {
+	return newTween(target, durationMs, func() func(progress float64) {
+		fromWidth, fromHeight := target.GetSize()
+		return func(progress float64) {
+			target.SetSize(
+				int32(math.Round(lerp(float64(fromWidth), float64(width), progress))),
+				int32(math.Round(lerp(float64(fromHeight), float64(height), progress))),
+			)
+		}
+	})
+}
+
+// TweenAlpha changes the alpha of a color (e.g. &image.ColorMixin)
+func TweenAlpha(color *womixins.ColorMixin, alpha uint8, durationMs uint64) *Tween {
+	return newTween(color, durationMs, func() func(progress float64) {
+		fromAlpha := color.A
+		return func(progress float64) {
+			color.SetAlpha(lerpColorChannel(fromAlpha, alpha, progress))
+		}
+	})
+}
+
+// TweenColor changes the color, keeping the alpha (e.g. a damage flash on &image.ColorMixin)
+func TweenColor(color *womixins.ColorMixin, r, g, b uint8, durationMs uint64) *Tween {
+	return newTween(color, durationMs, func() func(progress float64) {
+		fromR, fromG, fromB := color.R, color.G, color.B
+		return func(progress float64) {
+			color.SetColor(lerpColorChannel(fromR, r, progress), lerpColorChannel(fromG, g, progress), lerpColorChannel(fromB, b, progress))
+		}
+	})
+}
+
+// lerpColorChannel returns the channel at progress, clamped to 0-255 (easings like EaseOutBack go past the end)
+func lerpColorChannel(from, to uint8, progress float64) uint8 {
+	return uint8(min(max(math.Round(lerp(float64(from), float64(to), progress)), 0), 255))
+}
+
+// TweenFadeIn shows the target, changing its alpha from 0 to 255
+func TweenFadeIn(target womixins.Hideable, color *womixins.ColorMixin, durationMs uint64) *Tween {
+	return newTween(color, durationMs, func() func(progress float64) {
+		target.Show()
+		return func(progress float64) {
+			color.SetAlpha(lerpColorChannel(0, 255, progress))
+		}
+	})
+}
+
+// TweenFadeOut changes the alpha of the target to 0, then hides it
+func TweenFadeOut(target womixins.Hideable, color *womixins.ColorMixin, durationMs uint64) *Tween {
+	return newTween(color, durationMs, func() func(progress float64) {
+		fromAlpha := color.A
+		return func(progress float64) {
+			color.SetAlpha(lerpColorChannel(fromAlpha, 0, progress))
+			if progress >= 1 {
+				target.Hide()
+			}
+		}
+	})
+}
+
+// TweenCameraTranslation moves the camera to a translation (see GameCamera.SetTranslation)
+func TweenCameraTranslation(camera *GameCamera, x, y int32, durationMs uint64) *Tween {
+	return newTween(camera, durationMs, func() func(progress float64) {
+		fromX, fromY := camera.GetTranslation()
+		return func(progress float64) {
+			camera.SetTranslation(
+				int32(math.Round(lerp(float64(fromX), float64(x), progress))),
+				int32(math.Round(lerp(float64(fromY), float64(y), progress))),
+			)
+		}
+	})
+}
+
+// TweenCameraCenter pans the camera until the point of the world is at the center of a screen of the given size
+// (see GameCamera.CenterOn). The zoom is read when the pan starts
+func TweenCameraCenter(camera *GameCamera, x, y, screenWidth, screenHeight int32, durationMs uint64) *Tween {
+	return newTween(camera, durationMs, func() func(progress float64) {
+		fromX, fromY := camera.GetTranslation()
+		toX := int32(float32(screenWidth)/2/camera.zoom) - x
+		toY := int32(float32(screenHeight)/2/camera.zoom) - y
+		return func(progress float64) {
+			camera.SetTranslation(
+				int32(math.Round(lerp(float64(fromX), float64(toX), progress))),
+				int32(math.Round(lerp(float64(fromY), float64(toY), progress))),
+			)
+		}
+	})
+}
+
+// TweenCameraZoom changes the zoom of the camera (see GameCamera.SetZoom)
+func TweenCameraZoom(camera *GameCamera, zoom float32, durationMs uint64) *Tween {
+	return newTween(camera, durationMs, func() func(progress float64) {
+		fromZoom := camera.GetZoom()
+		return func(progress float64) {
+			camera.SetZoom(float32(lerp(float64(fromZoom), float64(zoom), progress)))
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package woutils
+
+import (
+	"math"
+	"testing"
+)
+
+// newTestTween creates a linear tween from 0 to 10, keeping its value in value
+func newTestTween(durationMs uint64, value *float64) *Tween {
+	tween := TweenValue(0, 10, durationMs, func(newValue float64) {
+		*value = newValue
+	})
+	tween.SetEasing(EaseLinear)
+	return tween
+}
+
+func TestTweenAdvance(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(tween *Tween)
+		steps    []float64 // Milliseconds of each advance
+		value    float64   // After the steps
+		leftover float64   // Returned by the last step
+		finished bool
+	}{
+		{"halfway", nil, []float64{50}, 5, 0, false},
+		{"in steps", nil, []float64{20, 30, 25}, 7.5, 0, false},
+		{"ends exactly", nil, []float64{60, 40}, 10, 0, true},
+		{"returns the leftover", nil, []float64{150}, 10, 50, true},
+		{"waits the delay", func(tween *Tween) { tween.SetDelay(50) }, []float64{75}, 2.5, 0, false},
+		{"delay and leftover", func(tween *Tween) { tween.SetDelay(50) }, []float64{175}, 10, 25, true},
+		{"repeats", func(tween *Tween) { tween.SetRepeat(1) }, []float64{130}, 3, 0, false},
+		{"ends after the repeats", func(tween *Tween) { tween.SetRepeat(2) }, []float64{120, 100, 100}, 10, 20, true},
+		{"repeats forever", func(tween *Tween) { tween.SetRepeat(-1) }, []float64{1040}, 4, 0, false},
+		{"yoyo goes back", func(tween *Tween) { tween.SetRepeat(1); tween.SetYoyo(true) }, []float64{125}, 7.5, 0, false},
+		{"yoyo ends at the start", func(tween *Tween) { tween.SetRepeat(1); tween.SetYoyo(true) }, []float64{210}, 0, 10, true},
+		{"yoyo forth again", func(tween *Tween) { tween.SetRepeat(2); tween.SetYoyo(true) }, []float64{230}, 3, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var value float64
+			tween := newTestTween(100, &value)
+			if test.setup != nil {
+				test.setup(tween)
+			}
+			tween.reset()
+
+			var leftover float64
+			for _, step := range test.steps {
+				leftover = tween.advance(step)
+			}
+
+			if math.Abs(value-test.value) > 1e-9 {
+				t.Errorf("value = %v, expected %v", value, test.value)
+			}
+			if math.Abs(leftover-test.leftover) > 1e-9 {
+				t.Errorf("leftover = %v, expected %v", leftover, test.leftover)
+			}
+			if tween.IsFinished() != test.finished {
+				t.Errorf("finished = %v, expected %v", tween.IsFinished(), test.finished)
+			}
+		})
+	}
+}
+
+func TestTweenSequence(t *testing.T) {
+	var first, second float64
+	sequence := NewSequence(newTestTween(100, &first), NewDelay(50), newTestTween(100, &second))
+	completed := 0
+	sequence.OnComplete(func() { completed++ })
+	sequence.reset()
+
+	steps := []struct {
+		deltaMs  float64
+		first    float64
+		second   float64
+		finished bool
+	}{
+		{80, 8, 0, false},
+		{60, 10, 0, false}, // The leftover of the first tween is used by the delay
+		{30, 10, 2, false},
+		{200, 10, 10, true},
+	}
+
+	for index, step := range steps {
+		sequence.advance(step.deltaMs)
+		if first != step.first || math.Abs(second-step.second) > 1e-9 || sequence.IsFinished() != step.finished {
+			t.Fatalf("step %d: first = %v, second = %v, finished = %v, expected %v, %v, %v",
+				index, first, second, sequence.IsFinished(), step.first, step.second, step.finished)
+		}
+	}
+
+	if completed != 1 {
+		t.Fatalf("OnComplete called %d times, expected 1", completed)
+	}
+}
+
+func TestTweenRepeatedSequence(t *testing.T) {
+	var first, second float64
+	sequence := NewSequence(newTestTween(100, &first), newTestTween(100, &second))
+	sequence.SetRepeat(1)
+	sequence.reset()
+
+	// The second play starts from the beginning, with the leftover of the first play
+	if leftover := sequence.advance(250); leftover != 0 || first != 5 || second != 10 {
+		t.Fatalf("first = %v, second = %v, leftover = %v, expected 5, 10, 0", first, second, leftover)
+	}
+	if leftover := sequence.advance(200); leftover != 50 || !sequence.IsFinished() {
+		t.Fatalf("leftover = %v, finished = %v, expected 50, true", leftover, sequence.IsFinished())
+	}
+}
+
+func TestTweenParallel(t *testing.T) {
+	var short, long float64
+	parallel := NewParallel(newTestTween(100, &short), newTestTween(200, &long))
+	parallel.reset()
+
+	if parallel.advance(150); short != 10 || long != 7.5 || parallel.IsFinished() {
+		t.Fatalf("short = %v, long = %v, finished = %v, expected 10, 7.5, false", short, long, parallel.IsFinished())
+	}
+	if leftover := parallel.advance(80); leftover != 30 || long != 10 || !parallel.IsFinished() {
+		t.Fatalf("long = %v, leftover = %v, finished = %v, expected 10, 30, true", long, leftover, parallel.IsFinished())
+	}
+}
+
+func TestTweenCancel(t *testing.T) {
+	var value float64
+	tween := newTestTween(100, &value)
+	completed := false
+	tween.OnComplete(func() { completed = true })
+	tween.reset()
+
+	tween.advance(40)
+	tween.Cancel()
+	tween.advance(100)
+
+	if value != 4 || completed || tween.IsFinished() || !tween.IsCancelled() {
+		t.Fatalf("value = %v, completed = %v, finished = %v, expected the tween to stop at 4", value, completed, tween.IsFinished())
+	}
+}
+
+func TestUpdateTweensUsesGameTime(t *testing.T) {
+	context := &GameContext{}
+
+	var gameValue, realValue float64
+	gameTween := newTestTween(100, &gameValue)
+	realTween := newTestTween(100, &realValue)
+	realTween.SetUseRealTime(true)
+	context.PlayTween(gameTween)
+	context.PlayTween(realTween)
+
+	// Paused, the game time does not pass
+	context.updateTweens(0, 50)
+	if gameValue != 0 || realValue != 5 {
+		t.Fatalf("game value = %v, real value = %v, expected 0 and 5", gameValue, realValue)
+	}
+
+	context.updateTweens(100, 50)
+	if gameValue != 10 || realValue != 10 || len(context.tweens) != 0 {
+		t.Fatalf("game value = %v, real value = %v, playing = %d, expected both tweens to end",
+			gameValue, realValue, len(context.tweens))
+	}
+}
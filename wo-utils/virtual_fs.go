@@ -0,0 +1,409 @@
+package woutils
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type vfsMount struct {
+	name       string
+	fsys       fs.FS
+	mountPoint string // Directory of the virtual file system where the files appear. "" is the root
+	priority   int
+	closer     io.Closer // Closes the mounted archive, if any
+}
+
+// translate returns the path in the mount of the virtual path name
+func (vm *vfsMount) translate(name string) (string, bool) {
+	switch {
+	case vm.mountPoint == "":
+		return name, true
+	case name == vm.mountPoint:
+		return ".", true
+	case strings.HasPrefix(name, vm.mountPoint+"/"):
+		return name[len(vm.mountPoint)+1:], true
+	}
+	return "", false
+}
+
+// FileConflict is a file supplied by more than one mount
+type FileConflict struct {
+	Path   string
+	Mounts []string // Names of the mounts with the file, the one used first
+}
+
+// VirtualFS layers many file systems (directories, zip archives, embed.FS...) mounted in priority order.
+// A file is read from the mount with the highest priority that has it, so a mod only needs the files it
+// changes (e.g. one tileset PNG). Directories list the files of all the mounts.
+// It is an fs.FS, so it can be used by all the loaders (e.g. NewImageFromFs or NewGameMapFromFs).
+//
+//	vfs := NewVirtualFS()
+//	vfs.Mount("base", baseAssets, "", 0)           // e.g. an embed.FS
+//	vfs.MountZip("dlc", "dlc/islands.zip", "", 10)
+//	vfs.MountDir("my-mod", "mods/my-mod", "", 20)
+type VirtualFS struct {
+	mutex  sync.RWMutex
+	mounts []*vfsMount // Sorted by priority, highest first. Mounts with the same priority: the last mounted first
+}
+
+func NewVirtualFS() *VirtualFS {
+	return &VirtualFS{
+		mounts: nil,
+	}
+}
+
+// Mount adds fsys, with its files under mountPoint ("" for the root). Files of mounts with higher
+// priority replace the files of mounts with lower priority. With the same priority, the last mount wins
+func (v *VirtualFS) Mount(name string, fsys fs.FS, mountPoint string, priority int) error {
+	return v.mount(&vfsMount{name: name, fsys: fsys, mountPoint: cleanMountPoint(mountPoint), priority: priority})
+}
+
+// MountDir mounts a directory of the operating system (see Mount)
+func (v *VirtualFS) MountDir(name string, dir string, mountPoint string, priority int) error {
+	if info, err := os.Stat(dir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return v.Mount(name, os.DirFS(dir), mountPoint, priority)
+}
+
+// MountZip mounts the files of a zip archive (see Mount). The archive is kept open until it is unmounted
+func (v *VirtualFS) MountZip(name string, zipPath string, mountPoint string, priority int) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+
+	err = v.mount(&vfsMount{name: name, fsys: reader, mountPoint: cleanMountPoint(mountPoint), priority: priority, closer: reader})
+	if err != nil {
+		reader.Close()
+	}
+	return err
+}
+
+func (v *VirtualFS) mount(newMount *vfsMount) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	for _, mount := range v.mounts {
+		if mount.name == newMount.name {
+			return fmt.Errorf("a file system is already mounted as %s", newMount.name)
+		}
+	}
+
+	index := 0
+	for index < len(v.mounts) && v.mounts[index].priority > newMount.priority {
+		index++
+	}
+	v.mounts = slices.Insert(v.mounts, index, newMount)
+	return nil
+}
+
+// Unmount removes the mount named name. Returns false if there is no such mount
+func (v *VirtualFS) Unmount(name string) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	for index, mount := range v.mounts {
+		if mount.name == name {
+			if mount.closer != nil {
+				mount.closer.Close()
+			}
+			v.mounts = slices.Delete(v.mounts, index, index+1)
+			return true
+		}
+	}
+	return false
+}
+
+// GetMounts returns the names of the mounts, in the order files are searched (highest priority first)
+func (v *VirtualFS) GetMounts() []string {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	names := make([]string, len(v.mounts))
+	for index, mount := range v.mounts {
+		names[index] = mount.name
+	}
+	return names
+}
+
+// Close unmounts everything, closing the mounted archives
+func (v *VirtualFS) Close() error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	var errs []error
+	for _, mount := range v.mounts {
+		if mount.closer != nil {
+			errs = append(errs, mount.closer.Close())
+		}
+	}
+	v.mounts = nil
+	return errors.Join(errs...)
+}
+
+// findFile returns the mount supplying the file name (not a directory), and the path in the mount
+func (v *VirtualFS) findFile(name string) (*vfsMount, string) {
+	for _, mount := range v.mounts {
+		relativePath, ok := mount.translate(name)
+		if !ok {
+			continue
+		}
+
+		if info, err := fs.Stat(mount.fsys, relativePath); err == nil {
+			if info.IsDir() {
+				return nil, ""
+			}
+			return mount, relativePath
+		}
+	}
+	return nil, ""
+}
+
+func (v *VirtualFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	if mount, relativePath := v.findFile(name); mount != nil {
+		return mount.fsys.Open(relativePath)
+	}
+
+	entries, err := v.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &vfsDir{info: vfsDirInfo{name: path.Base(name)}, entries: entries}, nil
+}
+
+func (v *VirtualFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	mount, relativePath := v.findFile(name)
+	if mount == nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return fs.ReadFile(mount.fsys, relativePath)
+}
+
+// ReadDir lists the files and directories of all the mounts in the directory name, sorted by name
+func (v *VirtualFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	entries, err := v.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+func (v *VirtualFS) readDir(name string) ([]fs.DirEntry, error) {
+	entriesByName := make(map[string]fs.DirEntry)
+	found := false
+
+	for _, mount := range v.mounts {
+		if relativePath, ok := mount.translate(name); ok {
+			mountEntries, err := fs.ReadDir(mount.fsys, relativePath)
+			if err != nil {
+				continue
+			}
+
+			found = true
+			for _, entry := range mountEntries {
+				if _, exists := entriesByName[entry.Name()]; !exists {
+					entriesByName[entry.Name()] = entry
+				}
+			}
+			continue
+		}
+
+		// The directories of the mount point are listed in their parents (e.g. "mods" for "mods/my-mod")
+		prefix := name + "/"
+		if name == "." {
+			prefix = ""
+		}
+		if rest, ok := strings.CutPrefix(mount.mountPoint, prefix); ok && mount.mountPoint != "" {
+			found = true
+			child, _, _ := strings.Cut(rest, "/")
+			if _, exists := entriesByName[child]; !exists {
+				entriesByName[child] = fs.FileInfoToDirEntry(vfsDirInfo{name: child})
+			}
+		}
+	}
+
+	if !found {
+		return nil, fs.ErrNotExist
+	}
+
+	entries := make([]fs.DirEntry, 0, len(entriesByName))
+	for _, entry := range entriesByName {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+func (v *VirtualFS) Stat(name string) (fs.FileInfo, error) {
+	file, err := v.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return file.Stat()
+}
+
+// ListFiles returns the paths of all the files under the directory dir ("." for all the files)
+func (v *VirtualFS) ListFiles(dir string) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(v, dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			files = append(files, filePath)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// GetSource returns the name of the mount the file name is read from. Returns false if there is no such file
+func (v *VirtualFS) GetSource(name string) (string, bool) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	if mount, _ := v.findFile(name); mount != nil {
+		return mount.name, true
+	}
+	return "", false
+}
+
+// GetProviders returns the names of all the mounts with the file name, the one it is read from first
+func (v *VirtualFS) GetProviders(name string) []string {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	var providers []string
+	for _, mount := range v.mounts {
+		if relativePath, ok := mount.translate(name); ok {
+			if info, err := fs.Stat(mount.fsys, relativePath); err == nil && !info.IsDir() {
+				providers = append(providers, mount.name)
+			}
+		}
+	}
+	return providers
+}
+
+// GetConflicts returns the files supplied by more than one mount (e.g. the files a mod replaces), sorted by path
+func (v *VirtualFS) GetConflicts() []FileConflict {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	mountsByFile := make(map[string][]string)
+	for _, mount := range v.mounts {
+		fs.WalkDir(mount.fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			if mount.mountPoint != "" {
+				filePath = mount.mountPoint + "/" + filePath
+			}
+			mountsByFile[filePath] = append(mountsByFile[filePath], mount.name)
+			return nil
+		})
+	}
+
+	var conflicts []FileConflict
+	for filePath, mounts := range mountsByFile {
+		if len(mounts) > 1 {
+			conflicts = append(conflicts, FileConflict{Path: filePath, Mounts: mounts})
+		}
+	}
+	slices.SortFunc(conflicts, func(a, b FileConflict) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return conflicts
+}
+
+func cleanMountPoint(mountPoint string) string {
+	mountPoint = path.Clean(strings.Trim(mountPoint, "/"))
+	if mountPoint == "." {
+		return ""
+	}
+	return mountPoint
+}
+
+// vfsDirInfo describes the directories merged from many mounts
+type vfsDirInfo struct {
+	name string
+}
+
+func (vdi vfsDirInfo) Name() string       { return vdi.name }
+func (vdi vfsDirInfo) Size() int64        { return 0 }
+func (vdi vfsDirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (vdi vfsDirInfo) ModTime() time.Time { return time.Time{} }
+func (vdi vfsDirInfo) IsDir() bool        { return true }
+func (vdi vfsDirInfo) Sys() any           { return nil }
+
+// vfsDir is an open directory of the VirtualFS
+type vfsDir struct {
+	info    vfsDirInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (vd *vfsDir) Stat() (fs.FileInfo, error) {
+	return vd.info, nil
+}
+
+func (vd *vfsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: vd.info.name, Err: errors.New("is a directory")}
+}
+
+func (vd *vfsDir) Close() error {
+	return nil
+}
+
+func (vd *vfsDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := vd.entries[vd.offset:]
+	if count <= 0 {
+		vd.offset = len(vd.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	count = min(count, len(remaining))
+	vd.offset += count
+	return remaining[:count], nil
+}